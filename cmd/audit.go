@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/eazyhozy/sekret/internal/audit"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// auditProfile is recorded with every event; sekret has a single profile.
+const auditProfile = "default"
+
+var (
+	auditKey    string
+	auditOp     string
+	auditSince  string
+	auditJSON   bool
+	auditVerify bool
+)
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Show the log of key changes and reads",
+	Long: `Show the audit log. sekret records every add, set, remove and import, and
+every read by env, get, run, inject, redact, proxy, the credential helpers
+and the MCP server, with the time, the keys involved, the calling process
+and (for run and the credential helpers) the program that received the keys.
+
+The log is append-only and hash-chained: each event includes the hash of
+the previous one, and a head record beside the log holds the event count
+and the last hash, so edited, deleted or truncated events are detected by
+--verify.
+
+  sekret audit --key OPENAI_API_KEY --since 168h
+  sekret audit --verify`,
+	Args: cobra.NoArgs,
+	RunE: runAudit,
+}
+
+func init() {
+	auditCmd.Flags().StringVar(&auditKey, "key", "", "only events involving this key")
+	auditCmd.Flags().StringVar(&auditOp, "op", "", "only events of this operation (add, set, rollback, remove, restore, import, read)")
+	auditCmd.Flags().StringVar(&auditSince, "since", "", "only events newer than this duration, e.g. 24h")
+	auditCmd.Flags().BoolVar(&auditJSON, "json", false, "print events as JSON lines")
+	auditCmd.Flags().BoolVar(&auditVerify, "verify", false, "check the hash chain instead of listing events")
+	rootCmd.AddCommand(auditCmd)
+}
+
+// recordAudit appends an event to the audit log. A failure is reported but
+// never blocks the operation being recorded.
+func recordAudit(op, target string, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	dir, err := config.Dir()
+	if err == nil {
+		pid, exe := audit.Caller()
+		err = audit.Append(audit.Path(dir), audit.Event{
+			Op:      op,
+			Keys:    keys,
+			Command: activeCommand,
+			Target:  target,
+			PID:     pid,
+			Exe:     exe,
+			Profile: auditProfile,
+		})
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(rootCmd.ErrOrStderr(), "sekret: warning: could not write audit log: %v\n", err)
+	}
+}
+
+// namedKey returns the key a CLI argument refers to, without its value,
+// so that single-key reads are audited by the same rule as auditKeys.
+func namedKey(cfg *config.Config, arg string) keyValue {
+	entry, err := resolveKey(cfg, arg)
+	return keyValue{envVar: canonicalName(cfg, arg), plain: err == nil && entry.IsPlain()}
+}
+
+// auditKeys returns the env vars of values that are secrets; plain
+// variables are not recorded.
+func auditKeys(values []keyValue) []string {
+	keys := make([]string, 0, len(values))
+	for _, kv := range values {
+		if !kv.plain {
+			keys = append(keys, kv.envVar)
+		}
+	}
+	return keys
+}
+
+func runAudit(c *cobra.Command, _ []string) error {
+	dir, err := config.Dir()
+	if err != nil {
+		return err
+	}
+	events, err := audit.Read(audit.Path(dir))
+	if err != nil {
+		return err
+	}
+
+	if auditVerify {
+		if err := audit.Verify(events); err != nil {
+			return fmt.Errorf("audit log has been tampered with: %w", err)
+		}
+		if err := audit.VerifyHead(audit.Path(dir), events); err != nil {
+			return fmt.Errorf("audit log has been tampered with: %w", err)
+		}
+		_, _ = fmt.Fprintf(c.ErrOrStderr(), "  Audit log intact (%d events)\n", len(events))
+		return nil
+	}
+
+	var since time.Time
+	if auditSince != "" {
+		d, err := time.ParseDuration(auditSince)
+		if err != nil {
+			return fmt.Errorf("invalid --since %q: use a duration like 24h", auditSince)
+		}
+		since = time.Now().Add(-d)
+	}
+
+	events = slices.DeleteFunc(events, func(e audit.Event) bool {
+		return (auditKey != "" && !slices.Contains(e.Keys, auditKey)) ||
+			(auditOp != "" && e.Op != auditOp) ||
+			e.Time.Before(since)
+	})
+
+	if auditJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range events {
+			if err := enc.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(events) == 0 {
+		_, _ = fmt.Fprintln(c.ErrOrStderr(), "No audit events.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "Time\tOp\tKeys\tCommand\tCaller")
+	_, _ = fmt.Fprintln(w, "----\t--\t----\t-------\t------")
+	for _, e := range events {
+		command := e.Command
+		if e.Target != "" {
+			command += " -> " + e.Target
+		}
+		caller := fmt.Sprintf("pid %d", e.PID)
+		if e.Exe != "" {
+			caller += " " + e.Exe
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			e.Time.Local().Format(time.DateTime), e.Op, strings.Join(e.Keys, ", "), command, caller)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,246 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/eazyhozy/sekret/cmd"
+	"github.com/eazyhozy/sekret/internal/audit"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// auditEvents returns the events recorded in the test's audit log.
+func auditEvents(t *testing.T) []audit.Event {
+	t.Helper()
+	dir, err := config.Dir()
+	require.NoError(t, err)
+	events, err := audit.Read(audit.Path(dir))
+	require.NoError(t, err)
+	return events
+}
+
+func TestAudit_RecordsChangesAndReads(t *testing.T) {
+	setup(t)
+	cmd.SetReadPassword(func(_ string) (string, error) {
+		return "sk-test-key-12345678", nil
+	})
+
+	require.NoError(t, executeCmd(t, "add", "OPENAI_API_KEY"))
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "OPENAI_API_KEY"))
+		require.NoError(t, executeCmd(t, "run", "--", "true"))
+	})
+
+	events := auditEvents(t)
+	require.Len(t, events, 3)
+
+	assert.Equal(t, "add", events[0].Op)
+	assert.Equal(t, []string{"OPENAI_API_KEY"}, events[0].Keys)
+	assert.Equal(t, "add", events[0].Command)
+
+	assert.Equal(t, "read", events[1].Op)
+	assert.Equal(t, "get", events[1].Command)
+
+	assert.Equal(t, "read", events[2].Op)
+	assert.Equal(t, "run", events[2].Command)
+	assert.Equal(t, "true", events[2].Target)
+	assert.Equal(t, os.Getppid(), events[2].PID)
+
+	require.NoError(t, audit.Verify(events))
+}
+
+func TestAudit_PlainVariablesNotRecorded(t *testing.T) {
+	setup(t)
+	require.NoError(t, executeCmd(t, "add", "APP_ENV", "--plain", "staging"))
+
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "env"))
+	})
+
+	events := auditEvents(t)
+	require.Len(t, events, 1)
+	assert.Equal(t, "add", events[0].Op)
+}
+
+func TestAudit_FilterByKey(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+	seedKey(t, "GITHUB_TOKEN", "ghp_test")
+
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "OPENAI_API_KEY"))
+		require.NoError(t, executeCmd(t, "get", "GITHUB_TOKEN"))
+	})
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "audit", "--key", "GITHUB_TOKEN", "--json"))
+	})
+
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	require.Len(t, lines, 1)
+	var e audit.Event
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &e))
+	assert.Equal(t, []string{"GITHUB_TOKEN"}, e.Keys)
+}
+
+func TestAudit_VerifyDetectsTampering(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "OPENAI_API_KEY"))
+		require.NoError(t, executeCmd(t, "get", "OPENAI_API_KEY"))
+	})
+	require.NoError(t, executeCmd(t, "audit", "--verify"))
+
+	dir, err := config.Dir()
+	require.NoError(t, err)
+	path := audit.Path(dir)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	tampered := strings.Replace(string(data), `"op":"read"`, `"op":"set"`, 1)
+	require.NoError(t, os.WriteFile(path, []byte(tampered), 0o600))
+
+	err = executeCmd(t, "audit", "--verify")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 1")
+}
+
+func TestAudit_RecordsReferencedKeys(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+	t.Setenv("GH_TOKEN", "sekret://github")
+
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "true"))
+	})
+
+	events := auditEvents(t)
+	require.Len(t, events, 1)
+	assert.Equal(t, []string{"GITHUB_TOKEN"}, events[0].Keys)
+}
+
+func TestAudit_RecordsHelperReads(t *testing.T) {
+	tests := []struct {
+		name   string
+		run    func(t *testing.T)
+		target string
+		keys   []string
+	}{
+		{"git-credential", func(t *testing.T) {
+			seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+			_, _, err := executeGitCredential(t, "get", "protocol=https\nhost=github.com\n\n")
+			require.NoError(t, err)
+		}, "git", []string{"GITHUB_TOKEN"}},
+		{"docker-credential", func(t *testing.T) {
+			_, code := executeDockerCredential(t, "store", `{"ServerURL":"https://ghcr.io","Username":"octocat","Secret":"ghp_registry"}`)
+			require.Equal(t, 0, code)
+			_, code = executeDockerCredential(t, "get", "https://ghcr.io\n")
+			require.Equal(t, 0, code)
+		}, "docker", []string{"docker:https://ghcr.io"}},
+		{"aws-credential-process", func(t *testing.T) {
+			seedAWS(t)
+			captureStdout(t, func() {
+				require.NoError(t, executeCmd(t, "aws-credential-process", "aws-dev"))
+			})
+		}, "aws", []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"}},
+		{"inject", func(t *testing.T) {
+			seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+			require.NoError(t, executeCmd(t, "add", "APP_ENV", "--plain", "staging"))
+			tmpl := writeTemplate(t, `{{ sekret "github" }} {{ sekret "GITHUB_TOKEN" }} {{ sekret "APP_ENV" }}`)
+			captureStdout(t, func() {
+				require.NoError(t, executeCmd(t, "inject", "-i", tmpl))
+			})
+		}, "", []string{"GITHUB_TOKEN"}},
+		{"redact", func(t *testing.T) {
+			seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+			cmd.RootCmd().SetIn(strings.NewReader("ghp_abc123\n"))
+			t.Cleanup(func() { cmd.RootCmd().SetIn(nil) })
+			captureStdout(t, func() {
+				require.NoError(t, executeCmd(t, "redact"))
+			})
+		}, "", []string{"GITHUB_TOKEN"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup(t)
+			tt.run(t)
+
+			events := auditEvents(t)
+			require.NotEmpty(t, events)
+			last := events[len(events)-1]
+			assert.Equal(t, "read", last.Op)
+			assert.Equal(t, tt.target, last.Target)
+			assert.Equal(t, tt.keys, last.Keys)
+		})
+	}
+}
+
+func TestAudit_RecordsProxyReads(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-real")
+	dir, err := config.Dir()
+	require.NoError(t, err)
+	path := audit.Path(dir)
+
+	captureStdout(t, func() {
+		done := make(chan error, 1)
+		go func() { done <- executeCmd(t, "proxy") }()
+		require.Eventually(t, func() bool {
+			events, err := audit.Read(path)
+			return err == nil && len(events) > 0
+		}, 5*time.Second, 10*time.Millisecond)
+
+		self, err := os.FindProcess(os.Getpid())
+		require.NoError(t, err)
+		require.NoError(t, self.Signal(syscall.SIGTERM))
+		require.NoError(t, <-done)
+	})
+
+	events := auditEvents(t)
+	require.Len(t, events, 1)
+	assert.Equal(t, "read", events[0].Op)
+	assert.Equal(t, "proxy", events[0].Command)
+	assert.Equal(t, []string{"OPENAI_API_KEY"}, events[0].Keys)
+}
+
+func TestAudit_VerifyDetectsTruncation(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "OPENAI_API_KEY"))
+		require.NoError(t, executeCmd(t, "get", "OPENAI_API_KEY"))
+	})
+
+	dir, err := config.Dir()
+	require.NoError(t, err)
+	path := audit.Path(dir)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.SplitAfter(string(data), "\n")
+	require.NoError(t, os.WriteFile(path, []byte(lines[0]), 0o600))
+
+	err = executeCmd(t, "audit", "--verify")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "log ends at event 1, but 2 were recorded")
+}
+
+func TestAudit_GetPlainVariableNotRecorded(t *testing.T) {
+	setup(t)
+	require.NoError(t, executeCmd(t, "add", "APP_ENV", "--plain", "staging"))
+
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "APP_ENV"))
+	})
+
+	events := auditEvents(t)
+	require.Len(t, events, 1)
+	assert.Equal(t, "add", events[0].Op)
+}
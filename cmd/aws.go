@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	awsAccessKeyID     string
+	awsSecretAccessKey string
+	awsSessionToken    string
+)
+
+var awsCredentialProcessCmd = &cobra.Command{
+	Use:   "aws-credential-process [ENTRY]",
+	Short: "Print AWS credentials in credential_process format",
+	Long: `Print AWS credentials in the JSON format expected by the credential_process
+setting in ~/.aws/config, so the AWS CLI and SDKs read them from the keychain:
+
+  [profile dev]
+  credential_process = sekret aws-credential-process aws-dev
+
+ENTRY is a multi-field credential exporting AWS_ACCESS_KEY_ID,
+AWS_SECRET_ACCESS_KEY and optionally AWS_SESSION_TOKEN.
+
+Without ENTRY, the registered keys named by --access-key-id,
+--secret-access-key and --session-token are used. If ENTRY is a regular
+key, it is used as the secret access key.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runAWSCredentialProcess,
+}
+
+func init() {
+	awsCredentialProcessCmd.Flags().StringVar(&awsAccessKeyID, "access-key-id", "AWS_ACCESS_KEY_ID", "key holding the access key id")
+	awsCredentialProcessCmd.Flags().StringVar(&awsSecretAccessKey, "secret-access-key", "AWS_SECRET_ACCESS_KEY", "key holding the secret access key")
+	awsCredentialProcessCmd.Flags().StringVar(&awsSessionToken, "session-token", "AWS_SESSION_TOKEN", "key holding the session token (optional)")
+	rootCmd.AddCommand(awsCredentialProcessCmd)
+}
+
+// awsCredentials is the credential_process output document.
+type awsCredentials struct {
+	Version         int    `json:"Version"`
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken,omitempty"`
+}
+
+func runAWSCredentialProcess(_ *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	r := newValueResolver(cfg)
+	lookup := r.lookup
+	secretKey := awsSecretAccessKey
+
+	if len(args) == 1 {
+		entry, err := resolveKey(cfg, args[0])
+		if err != nil {
+			return err
+		}
+		if entry.IsMulti() {
+			fields, err := r.fields(entry)
+			if err != nil {
+				return err
+			}
+			lookup = func(name string) (string, error) {
+				if v, ok := fields[name]; ok {
+					return v, nil
+				}
+				return "", fmt.Errorf("field %q of %q is not set", name, entry.EnvVar)
+			}
+		} else {
+			secretKey = entry.EnvVar
+		}
+	}
+
+	creds := awsCredentials{Version: 1}
+	if creds.AccessKeyID, err = lookup(awsAccessKeyID); err != nil {
+		return fmt.Errorf("failed to read access key id: %w", err)
+	}
+	if creds.SecretAccessKey, err = lookup(secretKey); err != nil {
+		return fmt.Errorf("failed to read secret access key: %w", err)
+	}
+	keys := []keyValue{namedKey(cfg, awsAccessKeyID), namedKey(cfg, secretKey)}
+	if token, err := lookup(awsSessionToken); err == nil {
+		creds.SessionToken = token
+		keys = append(keys, namedKey(cfg, awsSessionToken))
+	}
+
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("failed to encode credentials: %w", err)
+	}
+	fmt.Println(string(data))
+	recordAudit("read", "aws", auditKeys(keys)...)
+	return nil
+}
@@ -0,0 +1,55 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseAWSOutput(t *testing.T, output string) map[string]any {
+	t.Helper()
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal([]byte(output), &doc))
+	return doc
+}
+
+func TestAWSCredentialProcess_MultiField(t *testing.T) {
+	setup(t)
+	seedAWS(t)
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "aws-credential-process", "aws-dev"))
+	})
+
+	doc := parseAWSOutput(t, output)
+	assert.Equal(t, float64(1), doc["Version"])
+	assert.Equal(t, "AKIAEXAMPLE12345", doc["AccessKeyId"])
+	assert.Equal(t, "secret-access-key", doc["SecretAccessKey"])
+	assert.Equal(t, "session-token", doc["SessionToken"])
+}
+
+func TestAWSCredentialProcess_RegularKeys(t *testing.T) {
+	setup(t)
+	seedKey(t, "AWS_ACCESS_KEY_ID", "AKIAEXAMPLE12345")
+	seedKey(t, "PROD_AWS_SECRET", "prod-secret")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "aws-credential-process", "--secret-access-key", "PROD_AWS_SECRET"))
+	})
+
+	doc := parseAWSOutput(t, output)
+	assert.Equal(t, "AKIAEXAMPLE12345", doc["AccessKeyId"])
+	assert.Equal(t, "prod-secret", doc["SecretAccessKey"])
+	assert.NotContains(t, doc, "SessionToken")
+}
+
+func TestAWSCredentialProcess_MissingSecret(t *testing.T) {
+	setup(t)
+	seedKey(t, "AWS_ACCESS_KEY_ID", "AKIAEXAMPLE12345")
+
+	err := executeCmd(t, "aws-credential-process")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "secret access key")
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage config file backups",
+	Long: `Every change to config.json keeps a timestamped copy of the previous
+version (the last 10 are kept). List them with 'sekret config backups' and
+roll back with 'sekret config restore'.`,
+}
+
+var configBackupsCmd = &cobra.Command{
+	Use:   "backups",
+	Short: "List config backups, newest first",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigBackups,
+}
+
+var configRestoreCmd = &cobra.Command{
+	Use:   "restore [N]",
+	Short: "Restore config backup N (default: the most recent)",
+	Long: `Replace config.json with a backup listed by 'sekret config backups'. The
+current config is backed up first, so a restore can be undone.
+
+Only the config is restored; keychain values are left as they are.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runConfigRestore,
+}
+
+func init() {
+	configCmd.AddCommand(configBackupsCmd, configRestoreCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+// migrateConfig upgrades a config written by an older sekret before any
+// command reads it. The config commands are exempt so that backups can
+// still be restored when the config is unreadable.
+func migrateConfig(c *cobra.Command) error {
+	if c == configCmd || c.Parent() == configCmd {
+		return nil
+	}
+	from, err := config.Migrate()
+	if err != nil {
+		return err
+	}
+	if from != 0 {
+		_, _ = fmt.Fprintf(c.ErrOrStderr(), "sekret: note: upgraded config from version %d (previous version kept in backups)\n", from)
+	}
+	return nil
+}
+
+func runConfigBackups(c *cobra.Command, _ []string) error {
+	backups, err := config.Backups()
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		_, _ = fmt.Fprintln(c.ErrOrStderr(), "No config backups.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "#\tSaved\tKeys")
+	_, _ = fmt.Fprintln(w, "-\t-----\t----")
+	for i, b := range backups {
+		keys := "(corrupt)"
+		if cfg, err := config.LoadBackup(b); err == nil {
+			keys = strconv.Itoa(len(cfg.Keys))
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s (%s)\t%s\n", i+1,
+			b.Time.Local().Format(time.DateTime), humanize.Time(b.Time), keys)
+	}
+	return w.Flush()
+}
+
+func runConfigRestore(c *cobra.Command, args []string) error {
+	backups, err := config.Backups()
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		return fmt.Errorf("no config backups to restore")
+	}
+
+	n := 1
+	if len(args) == 1 {
+		n, err = strconv.Atoi(args[0])
+		if err != nil || n < 1 || n > len(backups) {
+			return fmt.Errorf("invalid backup %q: choose 1-%d (see 'sekret config backups')", args[0], len(backups))
+		}
+	}
+	b := backups[n-1]
+
+	confirmed, err := readConfirm(fmt.Sprintf("  Replace config with the backup from %s? [y/N]: ",
+		b.Time.Local().Format(time.DateTime)))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		_, _ = fmt.Fprintln(c.ErrOrStderr(), "  Cancelled")
+		return nil
+	}
+
+	if err := withConfigLock(func() error { return config.RestoreBackup(b) }); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(c.ErrOrStderr(), "  Config restored")
+	return nil
+}
@@ -0,0 +1,100 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eazyhozy/sekret/cmd"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigRestore(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-one")
+	seedKey(t, "GITHUB_TOKEN", "ghp_two")
+	confirmWith(true)
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "config", "backups"))
+	})
+	assert.Contains(t, output, "1 ")
+
+	require.NoError(t, executeCmd(t, "config", "restore"))
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.NotNil(t, cfg.FindKeyByEnvVar("OPENAI_API_KEY"))
+	assert.Nil(t, cfg.FindKeyByEnvVar("GITHUB_TOKEN"))
+}
+
+func TestConfigRestore_InvalidIndex(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-one")
+	seedKey(t, "GITHUB_TOKEN", "ghp_two")
+
+	err := executeCmd(t, "config", "restore", "5")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "choose 1-")
+}
+
+func TestConfigRestore_NoBackups(t *testing.T) {
+	setup(t)
+
+	err := executeCmd(t, "config", "restore")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no config backups")
+}
+
+func TestNewerConfig_RefusedButRestorable(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-one")
+	seedKey(t, "GITHUB_TOKEN", "ghp_two")
+	dir, err := config.Dir()
+	require.NoError(t, err)
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version":99,"keys":[]}`), 0o600))
+
+	err = executeCmd(t, "list")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "newer sekret")
+
+	confirmWith(true)
+	require.NoError(t, executeCmd(t, "config", "restore"))
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "list"))
+	})
+}
+
+func TestConfigLock_NotHeldAcrossPrompts(t *testing.T) {
+	setup(t)
+	cmd.SetReadPassword(func(_ string) (string, error) {
+		unlock, err := config.Lock()
+		require.NoError(t, err, "the config lock is held while prompting")
+		unlock()
+		return "sk-test-key-12345678", nil
+	})
+
+	require.NoError(t, executeCmd(t, "add", "OPENAI_API_KEY"))
+}
+
+func TestConfigLock_RejectsChangeDuringPrompt(t *testing.T) {
+	setup(t)
+	cmd.SetReadPassword(func(_ string) (string, error) {
+		seedKey(t, "GITHUB_TOKEN", "ghp_abcdef1234567890")
+		return "sk-test-key-12345678", nil
+	})
+
+	err := executeCmd(t, "add", "OPENAI_API_KEY")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "changed by another sekret process")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.NotNil(t, cfg.FindKeyByEnvVar("GITHUB_TOKEN"))
+	assert.Nil(t, cfg.FindKeyByEnvVar("OPENAI_API_KEY"))
+	_, err = testStore.Get("OPENAI_API_KEY")
+	assert.Error(t, err, "the keychain write is rolled back")
+}
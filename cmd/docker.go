@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// errDockerCredentialsNotFound is the message Docker expects when a helper
+// has no credentials for a server.
+const errDockerCredentialsNotFound = "credentials not found in native keychain"
+
+var dockerCredentialCmd = &cobra.Command{
+	Use:   "docker-credential",
+	Short: "Docker credential helper backed by the OS keychain",
+	Long: `Act as a Docker credential helper, so registry logins are stored in the
+OS keychain instead of base64-encoded in ~/.docker/config.json.
+
+Link the binary as docker-credential-sekret somewhere on your PATH and set
+"credsStore": "sekret" in ~/.docker/config.json. Then 'docker login'
+stores credentials through sekret.`,
+}
+
+var dockerCredentialGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Print credentials for the server URL read from stdin",
+	Args:  cobra.NoArgs,
+	RunE:  runDockerCredentialGet,
+}
+
+var dockerCredentialStoreCmd = &cobra.Command{
+	Use:   "store",
+	Short: "Store credentials read as JSON from stdin",
+	Args:  cobra.NoArgs,
+	RunE:  runDockerCredentialStore,
+}
+
+var dockerCredentialEraseCmd = &cobra.Command{
+	Use:   "erase",
+	Short: "Erase credentials for the server URL read from stdin",
+	Args:  cobra.NoArgs,
+	RunE:  runDockerCredentialErase,
+}
+
+var dockerCredentialListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored server URLs and usernames as JSON",
+	Args:  cobra.NoArgs,
+	RunE:  runDockerCredentialList,
+}
+
+func init() {
+	dockerCredentialCmd.AddCommand(dockerCredentialGetCmd, dockerCredentialStoreCmd, dockerCredentialEraseCmd, dockerCredentialListCmd)
+	rootCmd.AddCommand(dockerCredentialCmd)
+}
+
+// dockerCredentials is the JSON document exchanged with Docker.
+type dockerCredentials struct {
+	ServerURL string `json:"ServerURL"`
+	Username  string `json:"Username"`
+	Secret    string `json:"Secret"`
+}
+
+// dockerError reports an error the way Docker credential helpers do:
+// the message on stdout and exit code 1.
+func dockerError(msg string) error {
+	fmt.Println(msg)
+	exitFunc(1)
+	return nil
+}
+
+// readServerURL reads the server URL sent by Docker on stdin.
+func readServerURL(r io.Reader) (string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to read server URL: %w", err)
+	}
+	serverURL := strings.TrimSpace(string(data))
+	if serverURL == "" {
+		return "", fmt.Errorf("no server URL given")
+	}
+	return serverURL, nil
+}
+
+func runDockerCredentialGet(c *cobra.Command, _ []string) error {
+	serverURL, err := readServerURL(c.InOrStdin())
+	if err != nil {
+		return dockerError(err.Error())
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return dockerError(err.Error())
+	}
+
+	login := cfg.FindRegistry(serverURL)
+	if login == nil {
+		return dockerError(errDockerCredentialsNotFound)
+	}
+	secret, err := store.Get(login.KeychainKey())
+	if err != nil {
+		return dockerError(errDockerCredentialsNotFound)
+	}
+
+	data, err := json.Marshal(dockerCredentials{ServerURL: serverURL, Username: login.Username, Secret: secret})
+	if err != nil {
+		return dockerError(err.Error())
+	}
+	fmt.Println(string(data))
+	recordAudit("read", "docker", login.KeychainKey())
+	return nil
+}
+
+func runDockerCredentialStore(c *cobra.Command, _ []string) error {
+	var creds dockerCredentials
+	if err := json.NewDecoder(c.InOrStdin()).Decode(&creds); err != nil {
+		return dockerError(fmt.Sprintf("failed to parse credentials: %v", err))
+	}
+	if creds.ServerURL == "" {
+		return dockerError("no server URL given")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return dockerError(err.Error())
+	}
+
+	login := config.RegistryLogin{ServerURL: creds.ServerURL, Username: creds.Username}
+	err = transaction("docker-store", func() error {
+		if err := store.Set(login.KeychainKey(), creds.Secret); err != nil {
+			return err
+		}
+		cfg.SetRegistry(login)
+		return config.Save(cfg)
+	})
+	if err != nil {
+		return dockerError(err.Error())
+	}
+	return nil
+}
+
+func runDockerCredentialErase(c *cobra.Command, _ []string) error {
+	serverURL, err := readServerURL(c.InOrStdin())
+	if err != nil {
+		return dockerError(err.Error())
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return dockerError(err.Error())
+	}
+
+	login := cfg.FindRegistry(serverURL)
+	if login == nil {
+		return dockerError(errDockerCredentialsNotFound)
+	}
+	err = transaction("docker-erase", func() error {
+		if err := store.Delete(login.KeychainKey()); err != nil {
+			return err
+		}
+		if err := cfg.RemoveRegistry(serverURL); err != nil {
+			return err
+		}
+		return config.Save(cfg)
+	})
+	if err != nil {
+		return dockerError(err.Error())
+	}
+	return nil
+}
+
+func runDockerCredentialList(_ *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return dockerError(err.Error())
+	}
+
+	logins := make(map[string]string, len(cfg.Registries))
+	for _, r := range cfg.Registries {
+		logins[r.ServerURL] = r.Username
+	}
+
+	data, err := json.Marshal(logins)
+	if err != nil {
+		return dockerError(err.Error())
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,149 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/eazyhozy/sekret/cmd"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/keychain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// executeDockerCredential pipes input through a docker-credential operation
+// and returns stdout and the exit code (0 if exitFunc was not called).
+func executeDockerCredential(t *testing.T, op, input string) (string, int) {
+	t.Helper()
+
+	code := 0
+	cmd.SetExitFunc(func(c int) { code = c })
+	cmd.RootCmd().SetIn(strings.NewReader(input))
+	t.Cleanup(func() {
+		cmd.SetExitFunc(os.Exit)
+		cmd.RootCmd().SetIn(nil)
+	})
+
+	stdout := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "docker-credential", op))
+	})
+	return stdout, code
+}
+
+func TestDockerCredential_StoreGetEraseRoundTrip(t *testing.T) {
+	setup(t)
+
+	_, code := executeDockerCredential(t, "store", `{"ServerURL":"https://ghcr.io","Username":"octocat","Secret":"ghp_registry"}`)
+	require.Equal(t, 0, code)
+
+	secret, err := testStore.Get("docker:https://ghcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_registry", secret)
+
+	stdout, code := executeDockerCredential(t, "get", "https://ghcr.io\n")
+	require.Equal(t, 0, code)
+	var creds map[string]string
+	require.NoError(t, json.Unmarshal([]byte(stdout), &creds))
+	assert.Equal(t, map[string]string{"ServerURL": "https://ghcr.io", "Username": "octocat", "Secret": "ghp_registry"}, creds)
+
+	_, code = executeDockerCredential(t, "erase", "https://ghcr.io")
+	require.Equal(t, 0, code)
+
+	_, err = testStore.Get("docker:https://ghcr.io")
+	assert.Error(t, err)
+	cfg, _ := config.Load()
+	assert.Empty(t, cfg.Registries)
+}
+
+func TestDockerCredential_GetNotFound(t *testing.T) {
+	setup(t)
+
+	stdout, code := executeDockerCredential(t, "get", "https://unknown.example.com")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "credentials not found in native keychain\n", stdout)
+}
+
+func TestDockerCredential_List(t *testing.T) {
+	setup(t)
+	executeDockerCredential(t, "store", `{"ServerURL":"https://ghcr.io","Username":"octocat","Secret":"a"}`)
+	executeDockerCredential(t, "store", `{"ServerURL":"registry.example.com","Username":"ci","Secret":"b"}`)
+
+	stdout, code := executeDockerCredential(t, "list", "")
+	require.Equal(t, 0, code)
+
+	var logins map[string]string
+	require.NoError(t, json.Unmarshal([]byte(stdout), &logins))
+	assert.Equal(t, map[string]string{"https://ghcr.io": "octocat", "registry.example.com": "ci"}, logins)
+}
+
+func TestDockerCredential_StoreInvalidJSON(t *testing.T) {
+	setup(t)
+
+	stdout, code := executeDockerCredential(t, "store", "not json")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "failed to parse credentials")
+}
+
+func TestDockerCredential_StoreRollsBackOnKeychainFailure(t *testing.T) {
+	setup(t)
+	executeDockerCredential(t, "store", `{"ServerURL":"https://ghcr.io","Username":"octocat","Secret":"old"}`)
+	faults := faultyStore(t)
+
+	// Backing up the old secret succeeds; writing the new one fails.
+	faults.FailOnceAfter(1)
+	_, code := executeDockerCredential(t, "store", `{"ServerURL":"https://ghcr.io","Username":"hubot","Secret":"new"}`)
+	assert.Equal(t, 1, code)
+
+	secret, err := testStore.Get("docker:https://ghcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, "old", secret)
+	cfg, _ := config.Load()
+	require.Len(t, cfg.Registries, 1)
+	assert.Equal(t, "octocat", cfg.Registries[0].Username)
+	assert.NoFileExists(t, journalPath(t))
+}
+
+// deleteHookStore runs a hook after the first deletion of a key.
+type deleteHookStore struct {
+	keychain.Store
+	key      string
+	onDelete func()
+}
+
+func (s *deleteHookStore) Delete(name string) error {
+	if err := s.Store.Delete(name); err != nil {
+		return err
+	}
+	if name == s.key && s.onDelete != nil {
+		s.onDelete()
+		s.onDelete = nil
+	}
+	return nil
+}
+
+func TestDockerCredential_EraseRollsBackWhenConfigCannotBeSaved(t *testing.T) {
+	setup(t)
+	executeDockerCredential(t, "store", `{"ServerURL":"https://ghcr.io","Username":"octocat","Secret":"ghp_registry"}`)
+	path, err := config.Path()
+	require.NoError(t, err)
+
+	// Another process changes the config while the secret is being deleted,
+	// so saving the config fails after the keychain item is gone.
+	cmd.SetStore(&deleteHookStore{Store: testStore, key: "docker:https://ghcr.io", onDelete: func() {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(path, append(data, '\n'), 0o600))
+	}})
+
+	_, code := executeDockerCredential(t, "erase", "https://ghcr.io")
+	assert.Equal(t, 1, code)
+
+	secret, err := testStore.Get("docker:https://ghcr.io")
+	require.NoError(t, err, "the secret should be restored")
+	assert.Equal(t, "ghp_registry", secret)
+	cfg, _ := config.Load()
+	assert.Len(t, cfg.Registries, 1)
+	assert.NoFileExists(t, journalPath(t))
+}
@@ -0,0 +1,248 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/registry"
+	"github.com/eazyhozy/sekret/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var doctorJSON bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the keychain, registered keys and shell setup for problems",
+	Long: `Check that the keychain is reachable and unlocked, that every registered
+key has a readable value in the expected format, that no value is stored
+twice, and that the shell loads sekret and does not override its keys.
+
+Every problem comes with a suggested fix. Exits with status 1 if any error
+is found (warnings alone exit 0).`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorJSON, "json", false, "print the report as JSON")
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// Doctor finding severities.
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// doctorProbeItem is written and deleted to check that the keychain works.
+const doctorProbeItem = "doctor:probe"
+
+// doctorFinding is a problem found by doctor.
+type doctorFinding struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Key      string `json:"key,omitempty"`
+	Message  string `json:"message"`
+	Fix      string `json:"fix"`
+}
+
+// doctorReport is the JSON output of doctor.
+type doctorReport struct {
+	OK       bool            `json:"ok"`
+	Keys     int             `json:"keys"`
+	Findings []doctorFinding `json:"findings"`
+}
+
+func runDoctor(_ *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	findings := checkBackend()
+	if len(findings) == 0 {
+		findings = append(findings, checkKeyValues(cfg)...)
+	}
+	findings = append(findings, checkShell(cfg)...)
+
+	report := doctorReport{OK: true, Keys: len(cfg.Keys), Findings: findings}
+	for _, f := range findings {
+		if f.Severity == severityError {
+			report.OK = false
+		}
+	}
+	if report.Findings == nil {
+		report.Findings = []doctorFinding{}
+	}
+
+	if doctorJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printDoctorReport(report)
+	}
+
+	if !report.OK {
+		exitFunc(1)
+	}
+	return nil
+}
+
+func printDoctorReport(report doctorReport) {
+	fmt.Printf("Checked %d %s.\n", report.Keys, pluralize(report.Keys, "key", "keys"))
+	if len(report.Findings) == 0 {
+		fmt.Println("\nNo problems found.")
+		return
+	}
+
+	fmt.Println()
+	for _, f := range report.Findings {
+		fmt.Printf("  %-9s %s\n", f.Severity+":", f.Message)
+		fmt.Printf("  %-9s %s\n", "fix:", f.Fix)
+	}
+	fmt.Printf("\n%d %s found.\n", len(report.Findings),
+		pluralize(len(report.Findings), "problem", "problems"))
+}
+
+// checkBackend writes, reads back and deletes a probe item to check that
+// the keychain is reachable and unlocked.
+func checkBackend() []doctorFinding {
+	const probe = "sekret doctor probe"
+	err := store.Set(doctorProbeItem, probe)
+	if err == nil {
+		var got string
+		if got, err = store.Get(doctorProbeItem); err == nil && got != probe {
+			err = fmt.Errorf("probe item read back differently")
+		}
+		_ = store.Delete(doctorProbeItem)
+	}
+	if err == nil {
+		return nil
+	}
+
+	fix := "unlock the OS keychain and run 'sekret doctor' again"
+	if runtime.GOOS == "linux" {
+		fix = "start and unlock a Secret Service provider (e.g. gnome-keyring or KWallet) and run 'sekret doctor' again"
+	}
+	return []doctorFinding{{
+		Check:    "backend",
+		Severity: severityError,
+		Message:  fmt.Sprintf("the keychain is not reachable or is locked: %v", err),
+		Fix:      fix,
+	}}
+}
+
+// checkKeyValues checks that every keychain value is readable, matches its
+// registry prefix format and is not stored under another name as well.
+func checkKeyValues(cfg *config.Config) []doctorFinding {
+	var findings []doctorFinding
+	owners := map[string][]string{} // value -> env vars holding it
+	var values []string             // distinct values in config order
+	for _, k := range cfg.Keys {
+		if !k.InKeychain() {
+			continue
+		}
+		value, err := store.Get(k.KeychainKey())
+		if err != nil {
+			findings = append(findings, doctorFinding{
+				Check:    "keychain",
+				Severity: severityError,
+				Key:      k.EnvVar,
+				Message:  fmt.Sprintf("%s has no readable value in the keychain", k.EnvVar),
+				Fix:      fmt.Sprintf("store it again with 'sekret set %s', or 'sekret remove %s'", k.EnvVar, k.EnvVar),
+			})
+			continue
+		}
+		if k.IsFile() || k.IsMulti() {
+			continue
+		}
+
+		if regEntry := registry.LookupByEnvVar(k.EnvVar); !registry.ValidateFormat(regEntry, value) {
+			findings = append(findings, doctorFinding{
+				Check:    "format",
+				Severity: severityWarning,
+				Key:      k.EnvVar,
+				Message: fmt.Sprintf("%s does not start with %s (stored: %s)", k.EnvVar,
+					strings.Join(regEntry.Prefixes, " or "), scanner.MaskValue(value)),
+				Fix: fmt.Sprintf("check the value and replace it with 'sekret set %s'", k.EnvVar),
+			})
+		}
+		if owners[value] == nil {
+			values = append(values, value)
+		}
+		owners[value] = append(owners[value], k.EnvVar)
+	}
+
+	for _, value := range values {
+		names := owners[value]
+		if len(names) < 2 {
+			continue
+		}
+		findings = append(findings, doctorFinding{
+			Check:    "duplicate",
+			Severity: severityWarning,
+			Key:      names[0],
+			Message:  fmt.Sprintf("%s hold the same value", strings.Join(names, ", ")),
+			Fix: fmt.Sprintf("keep one and derive the others, e.g. 'sekret remove %s' then 'sekret add %s --template \"${%s}\"'",
+				names[1], names[1], names[0]),
+		})
+	}
+	return findings
+}
+
+// checkShell checks that the user's rc file loads sekret and that no shell
+// config file exports a registered key in plaintext.
+func checkShell(cfg *config.Config) []doctorFinding {
+	var findings []doctorFinding
+	targets := scanner.DefaultTargets()
+
+	shell := loginShell()
+	rc, err := shellRCFile(shell)
+	if err == nil {
+		targets = append(targets, rc)
+	}
+	if err == nil && len(cfg.Keys) > 0 && !loadsSekret(rc) {
+		message := fmt.Sprintf("%s does not load sekret", shortenHome(rc))
+		others := slices.DeleteFunc(scanner.DefaultTargets(), func(p string) bool { return p == rc || !loadsSekret(p) })
+		if len(others) > 0 {
+			message += fmt.Sprintf(" (%s does, but %s reads %s)", shortenHome(others[0]), shell, shortenHome(rc))
+		}
+		findings = append(findings, doctorFinding{
+			Check:    "shell",
+			Severity: severityWarning,
+			Message:  message,
+			Fix:      fmt.Sprintf("run 'sekret init' to add '%s' to %s", shellLoadLine(shell), shortenHome(rc)),
+		})
+	}
+
+	exports, err := scanner.ScanFilesAll(targets)
+	if err != nil {
+		return findings
+	}
+	seen := map[string]bool{}
+	for _, f := range exports {
+		where := fmt.Sprintf("%s:%d", shortenHome(f.FilePath), f.Line)
+		if seen[where] || strings.Contains(f.Value, "sekret ") ||
+			(cfg.FindKeyByEnvVar(f.EnvVar) == nil && cfg.FindKeyByField(f.EnvVar) == nil) {
+			continue
+		}
+		seen[where] = true
+		findings = append(findings, doctorFinding{
+			Check:    "shadow",
+			Severity: severityWarning,
+			Key:      f.EnvVar,
+			Message:  fmt.Sprintf("%s exports %s, shadowing the sekret key", where, f.EnvVar),
+			Fix:      fmt.Sprintf("delete the export from %s ('sekret import' can do it)", shortenHome(f.FilePath)),
+		})
+	}
+	return findings
+}
@@ -0,0 +1,110 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eazyhozy/sekret/cmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type doctorReport struct {
+	OK       bool `json:"ok"`
+	Findings []struct {
+		Check    string `json:"check"`
+		Severity string `json:"severity"`
+		Key      string `json:"key"`
+		Fix      string `json:"fix"`
+	} `json:"findings"`
+}
+
+// setupDoctor gives doctor a fresh home directory with a zsh rc file.
+func setupDoctor(t *testing.T, zshrc string) (home string, exitCode *int) {
+	t.Helper()
+	setup(t)
+	home = t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/zsh")
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".zshrc"), []byte(zshrc), 0o600))
+
+	code := 0
+	cmd.SetExitFunc(func(c int) { code = c })
+	t.Cleanup(func() { cmd.SetExitFunc(os.Exit) })
+	return home, &code
+}
+
+func runDoctorJSON(t *testing.T) doctorReport {
+	t.Helper()
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "doctor", "--json"))
+	})
+	var report doctorReport
+	require.NoError(t, json.Unmarshal([]byte(output), &report))
+	return report
+}
+
+func TestDoctor_Healthy(t *testing.T) {
+	_, code := setupDoctor(t, "eval \"$(sekret env)\"\n")
+	seedKey(t, "OPENAI_API_KEY", "sk-proj-abc123")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "doctor"))
+	})
+	assert.Contains(t, output, "No problems found")
+	assert.Zero(t, *code)
+	_, err := testStore.Get("doctor:probe")
+	assert.Error(t, err, "probe item should be deleted")
+}
+
+func TestDoctor_Findings(t *testing.T) {
+	_, code := setupDoctor(t, "export GITHUB_TOKEN=\"ghp_plain\"\n")
+	seedKey(t, "OPENAI_API_KEY", "not-a-key")
+	seedKey(t, "COPY_API_KEY", "not-a-key")
+	seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+	seedKey(t, "MISSING_TOKEN", "x")
+	require.NoError(t, testStore.Delete("MISSING_TOKEN"))
+
+	report := runDoctorJSON(t)
+	assert.False(t, report.OK)
+	assert.Equal(t, 1, *code)
+
+	checks := map[string]string{}
+	for _, f := range report.Findings {
+		checks[f.Check] = f.Key
+		assert.NotEmpty(t, f.Fix)
+	}
+	assert.Equal(t, map[string]string{
+		"keychain":  "MISSING_TOKEN",
+		"format":    "OPENAI_API_KEY",
+		"duplicate": "OPENAI_API_KEY",
+		"shell":     "",
+		"shadow":    "GITHUB_TOKEN",
+	}, checks)
+}
+
+func TestDoctor_WrongRCFile(t *testing.T) {
+	home, code := setupDoctor(t, "")
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".bashrc"), []byte("eval \"$(sekret env)\"\n"), 0o600))
+	seedKey(t, "OPENAI_API_KEY", "sk-proj-abc123")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "doctor"))
+	})
+	assert.Contains(t, output, "~/.zshrc does not load sekret (~/.bashrc does")
+	assert.Contains(t, output, `run 'sekret init' to add 'eval "$(sekret env)"' to ~/.zshrc`)
+	assert.Zero(t, *code, "warnings alone pass")
+}
+
+func TestDoctor_BackendUnavailable(t *testing.T) {
+	setupDoctor(t, "eval \"$(sekret env)\"\n")
+	seedKey(t, "OPENAI_API_KEY", "sk-proj-abc123")
+	faultyStore(t).FailAfter(0)
+
+	report := runDoctorJSON(t)
+	assert.False(t, report.OK)
+	require.Len(t, report.Findings, 1)
+	assert.Equal(t, "backend", report.Findings[0].Check)
+}
@@ -1,26 +1,34 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/derive"
 	"github.com/spf13/cobra"
 )
 
+var envShell string
+
 var envCmd = &cobra.Command{
 	Use:   "env",
 	Short: "Output all keys as export statements",
 	Long: `Output all registered keys as shell export statements.
 
-Add this to your .zshrc:
-  eval "$(sekret env)"`,
+Add this to your .zshrc (or run 'sekret init'):
+  eval "$(sekret env)"
+
+For fish, add this to config.fish instead:
+  sekret env --shell fish | source`,
 	Args: cobra.NoArgs,
 	RunE: runEnv,
 }
 
 func init() {
+	envCmd.Flags().StringVar(&envShell, "shell", "", "syntax of the statements: sh (also for bash and zsh) or fish (default sh)")
 	rootCmd.AddCommand(envCmd)
 }
 
@@ -35,24 +43,225 @@ func shellEscape(s string) string {
 	return replacer.Replace(s)
 }
 
+// shellQuote quotes a value for use in an export statement.
+// Values containing newlines or other control characters use ANSI-C
+// quoting ($'...'), since a double-quoted string would keep a literal
+// carriage return or break on copy-paste.
+func shellQuote(s string) string {
+	if !strings.ContainsFunc(s, isControl) {
+		return `"` + shellEscape(s) + `"`
+	}
+
+	var b strings.Builder
+	b.WriteString("$'")
+	for _, r := range s {
+		switch r {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if isControl(r) {
+				fmt.Fprintf(&b, `\x%02x`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteString("'")
+	return b.String()
+}
+
+// fishQuote quotes a value for fish, where only \\ and \' are escapes
+// inside single quotes and newlines may appear literally.
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+func isControl(r rune) bool {
+	return r < 0x20 || r == 0x7f
+}
+
 func runEnv(_ *cobra.Command, _ []string) error {
+	format := func(kv keyValue) string { return fmt.Sprintf("export %s=%s", kv.envVar, shellQuote(kv.value)) }
+	switch envShell {
+	case "", "sh", "bash", "zsh":
+	case "fish":
+		format = func(kv keyValue) string { return fmt.Sprintf("set -gx %s %s", kv.envVar, fishQuote(kv.value)) }
+	default:
+		return fmt.Errorf("unsupported shell %q: use --shell sh or fish", envShell)
+	}
+
 	cfg, err := config.Load()
 	if err != nil {
 		return err
 	}
 
-	if len(cfg.Keys) == 0 {
-		return nil
+	var exported []keyValue
+	for _, kv := range loadKeyValues(cfg) {
+		if kv.file {
+			fmt.Fprintf(os.Stderr, "sekret: note: file secret %q is only available via 'sekret run'\n", kv.envVar)
+			continue
+		}
+		fmt.Println(format(kv))
+		exported = append(exported, kv)
 	}
 
-	for _, k := range cfg.Keys {
-		val, err := store.Get(k.KeychainKey())
+	recordAudit("read", "", auditKeys(exported)...)
+	return nil
+}
+
+// keyValue is a registered env var paired with its value from the keychain.
+// For file secrets, value is the file content rather than the env var value.
+type keyValue struct {
+	envVar string
+	value  string
+	file   bool
+	plain  bool
+}
+
+// loadKeyValues reads the values of all registered keys from the keychain
+// and evaluates derived entries. Keys that cannot be read are reported to
+// stderr and skipped.
+func loadKeyValues(cfg *config.Config) []keyValue {
+	r := newValueResolver(cfg)
+	values := make([]keyValue, 0, len(cfg.Keys))
+	for i := range cfg.Keys {
+		k := &cfg.Keys[i]
+		if k.IsMulti() {
+			fields, err := r.fields(k)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "sekret: warning: could not read key %q: %v\n", k.EnvVar, err)
+				continue
+			}
+			for _, f := range k.Fields {
+				if v, ok := fields[f]; ok {
+					values = append(values, keyValue{envVar: f, value: v})
+				}
+			}
+			continue
+		}
+
+		val, err := r.value(k)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "sekret: warning: could not read key %q: %v\n", k.EnvVar, err)
 			continue
 		}
-		fmt.Printf("export %s=\"%s\"\n", k.EnvVar, shellEscape(val))
+		values = append(values, keyValue{envVar: k.EnvVar, value: val, file: k.IsFile(), plain: k.IsPlain()})
+		if prev, ok := previousValue(k); ok {
+			values = append(values, keyValue{envVar: k.EnvVar + previousSuffix, value: prev})
+		}
 	}
+	return values
+}
 
-	return nil
+// valueResolver reads key values, caching them so that derived entries
+// referencing the same key only hit the keychain once.
+type valueResolver struct {
+	cfg       *config.Config
+	cache     map[string]string
+	resolving map[string]bool
+}
+
+func newValueResolver(cfg *config.Config) *valueResolver {
+	return &valueResolver{
+		cfg:       cfg,
+		cache:     map[string]string{},
+		resolving: map[string]bool{},
+	}
+}
+
+// value returns the entry's value: the keychain item for stored keys, or
+// the expanded template for derived entries. Sensitive entries must be
+// released by the user first.
+func (r *valueResolver) value(entry *config.KeyEntry) (string, error) {
+	if val, ok := r.cache[entry.EnvVar]; ok {
+		return val, nil
+	}
+
+	if entry.Sensitive {
+		if err := unlockSensitive(r.cfg, entry); err != nil {
+			return "", err
+		}
+	}
+
+	var val string
+	var err error
+	switch {
+	case entry.IsPlain():
+		val = entry.Value
+	case entry.IsDerived():
+		if r.resolving[entry.EnvVar] {
+			return "", fmt.Errorf("template for %q references itself", entry.EnvVar)
+		}
+		r.resolving[entry.EnvVar] = true
+		val, err = derive.Expand(entry.Template, r.lookup)
+		delete(r.resolving, entry.EnvVar)
+	default:
+		val, err = store.Get(entry.KeychainKey())
+	}
+	if err != nil {
+		return "", err
+	}
+
+	r.cache[entry.EnvVar] = val
+	return val, nil
+}
+
+// lookup returns the value of the key named by a CLI-style argument, or of
+// a field of a multi-field entry.
+func (r *valueResolver) lookup(arg string) (string, error) {
+	if entry, err := resolveKey(r.cfg, arg); err == nil {
+		if entry.IsMulti() {
+			return "", fmt.Errorf("%q has multiple fields (use one of %s)", entry.EnvVar, strings.Join(entry.Fields, ", "))
+		}
+		return r.value(entry)
+	}
+
+	entry := r.cfg.FindKeyByField(arg)
+	if entry == nil {
+		return "", fmt.Errorf("key %q is not registered", arg)
+	}
+	fields, err := r.fields(entry)
+	if err != nil {
+		return "", err
+	}
+	val, ok := fields[arg]
+	if !ok {
+		return "", fmt.Errorf("field %q of %q is not set", arg, entry.EnvVar)
+	}
+	return val, nil
+}
+
+// fields reads and decodes the keychain item of a multi-field entry.
+func (r *valueResolver) fields(entry *config.KeyEntry) (map[string]string, error) {
+	raw, err := r.value(entry)
+	if err != nil {
+		return nil, err
+	}
+	return decodeFields(raw)
+}
+
+// encodeFields serialises multi-field values into a single keychain item.
+func encodeFields(fields map[string]string) (string, error) {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode fields: %w", err)
+	}
+	return string(data), nil
+}
+
+// decodeFields parses a keychain item written by encodeFields.
+func decodeFields(raw string) (map[string]string, error) {
+	var fields map[string]string
+	if err := json.Unmarshal([]byte(raw), &fields); err != nil {
+		return nil, fmt.Errorf("failed to decode fields: %w", err)
+	}
+	return fields, nil
 }
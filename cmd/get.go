@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var getCmd = &cobra.Command{
+	Use:   "get <ENV_VAR>",
+	Short: "Print a single key value to stdout",
+	Long: `Print the value of a registered key, for piping and command substitution:
+
+  curl -H "Authorization: Bearer $(sekret get OPENAI_API_KEY)" https://api.openai.com/v1/models
+  sekret get GITHUB_TOKEN | pbcopy
+
+Fields of multi-field credentials can be read by their env var name.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGet,
+}
+
+func init() {
+	rootCmd.AddCommand(getCmd)
+}
+
+func runGet(_ *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	value, err := readKeyValue(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(value)
+	recordAudit("read", "", auditKeys([]keyValue{namedKey(cfg, args[0])})...)
+	return nil
+}
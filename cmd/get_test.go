@@ -0,0 +1,38 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet_PrintsValue(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "openai"))
+	})
+
+	assert.Equal(t, "sk-test123\n", output)
+}
+
+func TestGet_MultiField(t *testing.T) {
+	setup(t)
+	seedAWS(t)
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "AWS_ACCESS_KEY_ID"))
+	})
+
+	assert.Equal(t, "AKIAEXAMPLE12345\n", output)
+}
+
+func TestGet_NotRegistered(t *testing.T) {
+	setup(t)
+
+	err := executeCmd(t, "get", "MISSING_KEY")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not registered")
+}
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// defaultGitUsername is sent with token passwords; GitHub ignores the username.
+const defaultGitUsername = "x-access-token"
+
+// defaultGitCredentials apply when no mapping is configured for a host.
+var defaultGitCredentials = []config.GitCredential{
+	{Host: "github.com", Key: "GITHUB_TOKEN", Username: defaultGitUsername},
+}
+
+var gitCredentialUsername string
+
+var gitCredentialCmd = &cobra.Command{
+	Use:   "git-credential",
+	Short: "Git credential helper backed by registered keys",
+	Long: `Act as a git credential helper, so HTTPS git operations use registered keys
+instead of a plaintext ~/.git-credentials file:
+
+  git config --global credential.helper '!sekret git-credential'
+
+github.com uses GITHUB_TOKEN by default. Map other hosts with:
+
+  sekret git-credential map gitlab.example.com GITLAB_TOKEN
+
+The binary can also be linked as git-credential-sekret and configured with
+credential.helper=sekret.`,
+}
+
+var gitCredentialGetCmd = &cobra.Command{
+	Use:   "get",
+	Short: "Return credentials for a host (git credential protocol)",
+	Args:  cobra.NoArgs,
+	RunE:  runGitCredentialGet,
+}
+
+var gitCredentialStoreCmd = &cobra.Command{
+	Use:   "store",
+	Short: "Save a login for a mapped key that has no value yet (git credential protocol)",
+	Args:  cobra.NoArgs,
+	RunE:  runGitCredentialStore,
+}
+
+var gitCredentialEraseCmd = &cobra.Command{
+	Use:   "erase",
+	Short: "Report rejected credentials (git credential protocol)",
+	Args:  cobra.NoArgs,
+	RunE:  runGitCredentialErase,
+}
+
+var gitCredentialMapCmd = &cobra.Command{
+	Use:   "map <host> <ENV_VAR>",
+	Short: "Use a registered key as the password for a git host",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runGitCredentialMap,
+}
+
+func init() {
+	gitCredentialMapCmd.Flags().StringVar(&gitCredentialUsername, "username", defaultGitUsername, "username to send with the key")
+	gitCredentialCmd.AddCommand(gitCredentialGetCmd, gitCredentialStoreCmd, gitCredentialEraseCmd, gitCredentialMapCmd)
+	rootCmd.AddCommand(gitCredentialCmd)
+}
+
+// readGitAttributes reads key=value lines until a blank line or EOF.
+func readGitAttributes(r io.Reader) (map[string]string, error) {
+	attrs := map[string]string{}
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := s.Text()
+		if line == "" {
+			break
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid credential attribute %q", line)
+		}
+		attrs[key] = value
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read credential request: %w", err)
+	}
+	return attrs, nil
+}
+
+// gitCredentialFor returns the configured or default mapping for an HTTPS
+// request, or nil if sekret has no key for the host.
+func gitCredentialFor(cfg *config.Config, attrs map[string]string) *config.GitCredential {
+	if attrs["protocol"] != "https" {
+		return nil
+	}
+	if gc := cfg.FindGitCredential(attrs["host"]); gc != nil {
+		return gc
+	}
+	for i := range defaultGitCredentials {
+		if defaultGitCredentials[i].Host == attrs["host"] {
+			return &defaultGitCredentials[i]
+		}
+	}
+	return nil
+}
+
+func runGitCredentialGet(c *cobra.Command, _ []string) error {
+	attrs, err := readGitAttributes(c.InOrStdin())
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	gc := gitCredentialFor(cfg, attrs)
+	if gc == nil {
+		// Unknown host: print nothing so git falls through to other helpers.
+		return nil
+	}
+
+	password, err := readKeyValue(cfg, gc.Key)
+	if err != nil {
+		_, _ = fmt.Fprintf(c.ErrOrStderr(), "sekret: warning: could not read key %q for %s: %v\n", gc.Key, attrs["host"], err)
+		return nil
+	}
+
+	username := gc.Username
+	if username == "" {
+		username = defaultGitUsername
+	}
+	fmt.Printf("username=%s\npassword=%s\n", username, password)
+	recordAudit("read", "git", auditKeys([]keyValue{namedKey(cfg, gc.Key)})...)
+	return nil
+}
+
+func runGitCredentialStore(c *cobra.Command, _ []string) error {
+	attrs, err := readGitAttributes(c.InOrStdin())
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	gc := gitCredentialFor(cfg, attrs)
+	if gc == nil || attrs["password"] == "" {
+		return nil
+	}
+
+	entry, err := resolveKey(cfg, gc.Key)
+	if err != nil || !entry.InKeychain() || entry.IsMulti() || entry.IsFile() {
+		return nil
+	}
+	// A registered value is never replaced on git's behalf: any program
+	// speaking the credential protocol could otherwise overwrite it.
+	if current, err := store.Get(entry.KeychainKey()); err == nil {
+		if current != attrs["password"] {
+			_, _ = fmt.Fprintf(c.ErrOrStderr(), "sekret: not replacing %s with the credential git used for %s; update it with 'sekret set %s'\n",
+				entry.EnvVar, attrs["host"], entry.EnvVar)
+		}
+		return nil
+	}
+	if err := store.Set(entry.KeychainKey(), attrs["password"]); err != nil {
+		return err
+	}
+	recordAudit("set", "", entry.EnvVar)
+	return nil
+}
+
+func runGitCredentialErase(c *cobra.Command, _ []string) error {
+	attrs, err := readGitAttributes(c.InOrStdin())
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	// Registered keys are never deleted on git's behalf; a rejected token
+	// is more likely expired than unwanted.
+	if gc := gitCredentialFor(cfg, attrs); gc != nil {
+		_, _ = fmt.Fprintf(c.ErrOrStderr(), "sekret: %s rejected %s; update it with 'sekret set %s'\n", attrs["host"], gc.Key, gc.Key)
+	}
+	return nil
+}
+
+func runGitCredentialMap(c *cobra.Command, args []string) error {
+	host, arg := args[0], args[1]
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	entry, err := resolveKey(cfg, arg)
+	if err != nil {
+		return err
+	}
+
+	cfg.SetGitCredential(config.GitCredential{Host: host, Key: entry.EnvVar, Username: gitCredentialUsername})
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.ErrOrStderr(), "  %s -> %s\n", host, entry.EnvVar)
+	return nil
+}
@@ -0,0 +1,119 @@
+package cmd_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/eazyhozy/sekret/cmd"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// executeGitCredential runs a git-credential operation with the given protocol input.
+func executeGitCredential(t *testing.T, op, input string) (stdout, stderr string, err error) {
+	t.Helper()
+
+	var stderrBuf bytes.Buffer
+	cmd.RootCmd().SetIn(strings.NewReader(input))
+	cmd.RootCmd().SetErr(&stderrBuf)
+	t.Cleanup(func() {
+		cmd.RootCmd().SetIn(nil)
+		cmd.RootCmd().SetErr(nil)
+	})
+
+	stdout = captureStdout(t, func() {
+		err = executeCmd(t, "git-credential", op)
+	})
+	return stdout, stderrBuf.String(), err
+}
+
+func TestGitCredential_GetDefaultGitHub(t *testing.T) {
+	setup(t)
+	seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+
+	stdout, _, err := executeGitCredential(t, "get", "protocol=https\nhost=github.com\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, "username=x-access-token\npassword=ghp_abc123\n", stdout)
+}
+
+func TestGitCredential_GetUnknownHost(t *testing.T) {
+	setup(t)
+	seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+
+	stdout, _, err := executeGitCredential(t, "get", "protocol=https\nhost=example.com\n\n")
+	require.NoError(t, err)
+	assert.Empty(t, stdout)
+}
+
+func TestGitCredential_GetIgnoresNonHTTPS(t *testing.T) {
+	setup(t)
+	seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+
+	stdout, _, err := executeGitCredential(t, "get", "protocol=http\nhost=github.com\n\n")
+	require.NoError(t, err)
+	assert.Empty(t, stdout)
+}
+
+func TestGitCredential_MapHost(t *testing.T) {
+	setup(t)
+	seedKey(t, "GITLAB_TOKEN", "glpat-xyz")
+
+	require.NoError(t, executeCmd(t, "git-credential", "map", "gitlab.example.com", "GITLAB_TOKEN", "--username", "oauth2"))
+
+	cfg, _ := config.Load()
+	gc := cfg.FindGitCredential("gitlab.example.com")
+	require.NotNil(t, gc)
+	assert.Equal(t, "GITLAB_TOKEN", gc.Key)
+
+	stdout, _, err := executeGitCredential(t, "get", "protocol=https\nhost=gitlab.example.com\n\n")
+	require.NoError(t, err)
+	assert.Equal(t, "username=oauth2\npassword=glpat-xyz\n", stdout)
+}
+
+func TestGitCredential_MapUnregisteredKey(t *testing.T) {
+	setup(t)
+
+	err := executeCmd(t, "git-credential", "map", "gitlab.example.com", "GITLAB_TOKEN")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not registered")
+}
+
+func TestGitCredential_StoreKeepsKey(t *testing.T) {
+	setup(t)
+	seedKey(t, "GITHUB_TOKEN", "ghp_old")
+
+	_, stderr, err := executeGitCredential(t, "store", "protocol=https\nhost=github.com\nusername=x-access-token\npassword=ghp_new\n\n")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "not replacing GITHUB_TOKEN")
+
+	val, err := testStore.Get("GITHUB_TOKEN")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_old", val)
+}
+
+func TestGitCredential_StoreFillsMissingValue(t *testing.T) {
+	setup(t)
+	seedKey(t, "GITHUB_TOKEN", "ghp_old")
+	require.NoError(t, testStore.Delete("GITHUB_TOKEN"))
+
+	_, _, err := executeGitCredential(t, "store", "protocol=https\nhost=github.com\nusername=x-access-token\npassword=ghp_new\n\n")
+	require.NoError(t, err)
+
+	val, err := testStore.Get("GITHUB_TOKEN")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_new", val)
+}
+
+func TestGitCredential_EraseKeepsKey(t *testing.T) {
+	setup(t)
+	seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+
+	_, stderr, err := executeGitCredential(t, "erase", "protocol=https\nhost=github.com\n\n")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "sekret set GITHUB_TOKEN")
+
+	_, err = testStore.Get("GITHUB_TOKEN")
+	assert.NoError(t, err)
+}
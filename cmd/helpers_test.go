@@ -9,6 +9,7 @@ import (
 	"github.com/eazyhozy/sekret/cmd"
 	"github.com/eazyhozy/sekret/internal/config"
 	"github.com/eazyhozy/sekret/internal/keychain"
+	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
 )
@@ -30,12 +31,20 @@ func setup(t *testing.T) {
 	cmd.SetReadChoice(func(_ string) (string, error) {
 		return "", fmt.Errorf("readChoice not configured for this test")
 	})
+	cmd.SetReadApproval(func(_ string) (bool, error) {
+		return false, fmt.Errorf("readApproval not configured for this test")
+	})
+	cmd.SetReadTTYPassword(func(_ string) (string, error) {
+		return "", fmt.Errorf("readTTYPassword not configured for this test")
+	})
 	t.Cleanup(func() {
 		config.SetPath("")
 		cmd.SetStore(keychain.NewOSStore())
 		cmd.SetReadPassword(nil)
 		cmd.SetReadConfirm(nil)
 		cmd.SetReadChoice(nil)
+		cmd.SetReadApproval(nil)
+		cmd.SetReadTTYPassword(nil)
 		testStore = nil
 	})
 }
@@ -60,6 +69,29 @@ func seedLegacyKey(t *testing.T, name, envVar, value string) {
 	require.NoError(t, testStore.Set(name, value), "failed to set key in store")
 }
 
+// seedFileKey creates a file secret entry holding content.
+func seedFileKey(t *testing.T, envVar, content string) {
+	t.Helper()
+	cfg, err := config.Load()
+	require.NoError(t, err, "failed to load config")
+	require.NoError(t, cfg.AddEntry(config.KeyEntry{EnvVar: envVar, Kind: config.KindFile}), "failed to add key")
+	require.NoError(t, config.Save(cfg), "failed to save config")
+	require.NoError(t, testStore.Set(envVar, content), "failed to set key in store")
+}
+
+// passwordSequence returns a readPassword mock that returns answers in order.
+func passwordSequence(answers ...string) func(string) (string, error) {
+	i := 0
+	return func(_ string) (string, error) {
+		if i >= len(answers) {
+			return "", fmt.Errorf("unexpected readPassword call #%d", i+1)
+		}
+		answer := answers[i]
+		i++
+		return answer, nil
+	}
+}
+
 func captureStdout(t *testing.T, fn func()) string {
 	t.Helper()
 	old := os.Stdout
@@ -82,9 +114,22 @@ func executeCmd(t *testing.T, args ...string) error {
 	t.Helper()
 	rootCmd := cmd.RootCmd()
 	rootCmd.SetArgs(args)
-	rootCmd.Flags().Visit(func(f *pflag.Flag) { _ = f.Value.Set(f.DefValue) })
-	for _, c := range rootCmd.Commands() {
-		c.Flags().Visit(func(f *pflag.Flag) { _ = f.Value.Set(f.DefValue) })
-	}
+	resetFlags(rootCmd)
 	return rootCmd.Execute()
 }
+
+// resetFlags restores every flag set by a previous test to its default,
+// recursing into subcommands.
+func resetFlags(c *cobra.Command) {
+	c.Flags().Visit(func(f *pflag.Flag) {
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			_ = sv.Replace(nil)
+		} else {
+			_ = f.Value.Set(f.DefValue)
+		}
+		f.Changed = false
+	})
+	for _, sub := range c.Commands() {
+		resetFlags(sub)
+	}
+}
@@ -0,0 +1,220 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+// previousSuffix names the env var exporting a key's previous value during
+// a rotation overlap window.
+const previousSuffix = "_PREVIOUS"
+
+var rollbackTo int
+
+var historyCmd = &cobra.Command{
+	Use:   "history <ENV_VAR>",
+	Short: "Show previous values of a key (masked)",
+	Long: `Show the current and previous values of a key, masked. 'sekret set' keeps
+the values it replaces as versioned keychain items (5 per key by default),
+so a bad rotation can be undone with 'sekret rollback'.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runHistory,
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <ENV_VAR>",
+	Short: "Restore a previous value of a key",
+	Long: `Restore a previous value of a key: the most recent one, or the version
+given with --to (see 'sekret history'). The value being replaced is kept in
+the history, so a rollback can itself be rolled back.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRollback,
+}
+
+func init() {
+	rollbackCmd.Flags().IntVar(&rollbackTo, "to", 0, "version to restore (default: the most recent)")
+	rootCmd.AddCommand(historyCmd, rollbackCmd)
+}
+
+// replaceValue stores value as the entry's keychain value, keeping the old
+// one in its history, and saves the config.
+func replaceValue(cfg *config.Config, entry *config.KeyEntry, value string) error {
+	return transaction("set", func() error {
+		if err := archiveValue(cfg, entry); err != nil {
+			return err
+		}
+		if err := store.Set(entry.KeychainKey(), value); err != nil {
+			return err
+		}
+		entry.UpdatedAt = time.Now()
+		return config.Save(cfg)
+	})
+}
+
+// archiveValue copies the current keychain value of entry into a new
+// history version and drops versions beyond the configured limit. The
+// caller saves the config.
+func archiveValue(cfg *config.Config, entry *config.KeyEntry) error {
+	limit := cfg.MaxHistory()
+	if limit == 0 {
+		return nil
+	}
+	current, err := store.Get(entry.KeychainKey())
+	if err != nil {
+		return nil // nothing to keep
+	}
+
+	seq := 1
+	if n := len(entry.History); n > 0 {
+		seq = entry.History[n-1].Seq + 1
+	}
+	if err := store.Set(entry.HistoryKey(seq), current); err != nil {
+		return fmt.Errorf("failed to keep previous value: %w", err)
+	}
+	entry.History = append(entry.History, config.Version{Seq: seq, SetAt: entry.SetAt()})
+
+	for len(entry.History) > limit {
+		_ = store.Delete(entry.HistoryKey(entry.History[0].Seq))
+		entry.History = entry.History[1:]
+	}
+	return nil
+}
+
+// previousValue returns the most recent previous value of entry while it
+// is in a rotation overlap window.
+func previousValue(entry *config.KeyEntry) (string, bool) {
+	if entry.IsFile() || entry.IsMulti() || len(entry.History) == 0 || !time.Now().Before(entry.PreviousUntil) {
+		return "", false
+	}
+	value, err := store.Get(entry.HistoryKey(entry.History[len(entry.History)-1].Seq))
+	return value, err == nil
+}
+
+// previousOf returns the key whose previous value envVar exports, or nil.
+func previousOf(cfg *config.Config, envVar string) *config.KeyEntry {
+	base, ok := strings.CutSuffix(envVar, previousSuffix)
+	if !ok || cfg.FindKeyByEnvVar(envVar) != nil {
+		return nil
+	}
+	return cfg.FindKeyByEnvVar(base)
+}
+
+// describeValue returns a masked preview of a keychain value of entry.
+func describeValue(entry *config.KeyEntry, value string) string {
+	switch {
+	case entry.IsMulti():
+		return "(" + strings.Join(entry.Fields, ", ") + ")"
+	case entry.IsFile():
+		return fmt.Sprintf("(file, %s)", humanize.Bytes(uint64(len(value))))
+	default:
+		return scanner.MaskValue(value)
+	}
+}
+
+// historyEntry resolves arg to a key whose value lives in the keychain.
+func historyEntry(cfg *config.Config, arg string) (*config.KeyEntry, error) {
+	entry, err := resolveKey(cfg, arg)
+	if err != nil {
+		return nil, fmt.Errorf("key %q is not registered", arg)
+	}
+	if !entry.InKeychain() {
+		return nil, fmt.Errorf("%q is not stored in the keychain and has no history", entry.EnvVar)
+	}
+	return entry, nil
+}
+
+func runHistory(c *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	entry, err := historyEntry(cfg, args[0])
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "Version\tValue\tSet")
+	_, _ = fmt.Fprintln(w, "-------\t-----\t---")
+	current := "(unavailable)"
+	if val, err := store.Get(entry.KeychainKey()); err == nil {
+		current = describeValue(entry, val)
+	}
+	_, _ = fmt.Fprintf(w, "current\t%s\t%s\n", current, humanize.Time(entry.SetAt()))
+	for i := len(entry.History) - 1; i >= 0; i-- {
+		v := entry.History[i]
+		preview := "(unavailable)"
+		if val, err := store.Get(entry.HistoryKey(v.Seq)); err == nil {
+			preview = describeValue(entry, val)
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\n", v.Seq, preview, humanize.Time(v.SetAt))
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(entry.History) == 0 {
+		_, _ = fmt.Fprintln(c.ErrOrStderr(), "  No previous values.")
+	}
+	return nil
+}
+
+func runRollback(c *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+	entry, err := historyEntry(cfg, args[0])
+	if err != nil {
+		return err
+	}
+	if len(entry.History) == 0 {
+		return fmt.Errorf("%s has no previous values", entry.EnvVar)
+	}
+
+	seq := entry.History[len(entry.History)-1].Seq
+	if rollbackTo != 0 {
+		seq = rollbackTo
+	}
+	if entry.FindVersion(seq) == nil {
+		return fmt.Errorf("%s has no version %d (see 'sekret history %s')", entry.EnvVar, seq, entry.EnvVar)
+	}
+	value, err := store.Get(entry.HistoryKey(seq))
+	if err != nil {
+		return err
+	}
+
+	err = transaction("rollback", func() error {
+		if err := archiveValue(cfg, entry); err != nil {
+			return err
+		}
+		if err := store.Set(entry.KeychainKey(), value); err != nil {
+			return err
+		}
+		// The restored version is current again, so it leaves the history.
+		entry.History = slices.DeleteFunc(entry.History, func(v config.Version) bool { return v.Seq == seq })
+		entry.UpdatedAt = time.Now()
+		entry.PreviousUntil = time.Time{}
+		if err := config.Save(cfg); err != nil {
+			return err
+		}
+		_ = store.Delete(entry.HistoryKey(seq))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	recordAudit("rollback", "", entry.EnvVar)
+	_, _ = fmt.Fprintf(c.ErrOrStderr(), "  Restored version %d of %s\n", seq, entry.EnvVar)
+	return nil
+}
@@ -0,0 +1,150 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/eazyhozy/sekret/cmd"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rotate sets envVar to each value in turn with 'sekret set'.
+func rotate(t *testing.T, envVar string, values ...string) {
+	t.Helper()
+	for _, v := range values {
+		cmd.SetReadPassword(func(_ string) (string, error) { return v, nil })
+		require.NoError(t, executeCmd(t, "set", envVar))
+	}
+}
+
+func TestSet_KeepsHistory(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-first-value-1234")
+	rotate(t, "OPENAI_API_KEY", "sk-second-value-1234")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	entry := cfg.FindKeyByEnvVar("OPENAI_API_KEY")
+	require.Len(t, entry.History, 1)
+
+	old, err := testStore.Get(entry.HistoryKey(entry.History[0].Seq))
+	require.NoError(t, err)
+	assert.Equal(t, "sk-first-value-1234", old)
+}
+
+func TestHistory_MasksValues(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-first-value-1234")
+	rotate(t, "OPENAI_API_KEY", "sk-second-value-1234")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "history", "OPENAI_API_KEY"))
+	})
+
+	assert.Contains(t, output, "current")
+	assert.Contains(t, output, "1 ")
+	assert.NotContains(t, output, "sk-first-value-1234")
+	assert.NotContains(t, output, "sk-second-value-1234")
+}
+
+func TestHistory_Limit(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-value-0000000000")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.HistoryLimit = 2
+	require.NoError(t, config.Save(cfg))
+
+	rotate(t, "OPENAI_API_KEY", "sk-value-1111111111", "sk-value-2222222222", "sk-value-3333333333")
+
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	entry := cfg.FindKeyByEnvVar("OPENAI_API_KEY")
+	require.Len(t, entry.History, 2)
+	assert.Equal(t, 2, entry.History[0].Seq)
+	_, err = testStore.Get(entry.HistoryKey(1))
+	assert.Error(t, err, "pruned version should be deleted from the keychain")
+}
+
+func TestRollback_Latest(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-good-value-1234")
+	rotate(t, "OPENAI_API_KEY", "sk-bad-value-12345")
+
+	require.NoError(t, executeCmd(t, "rollback", "OPENAI_API_KEY"))
+
+	val, _ := testStore.Get("OPENAI_API_KEY")
+	assert.Equal(t, "sk-good-value-1234", val)
+
+	// The rolled-back value is kept, so the rollback can be undone.
+	require.NoError(t, executeCmd(t, "rollback", "OPENAI_API_KEY"))
+	val, _ = testStore.Get("OPENAI_API_KEY")
+	assert.Equal(t, "sk-bad-value-12345", val)
+}
+
+func TestRollback_ToVersion(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-value-0000000000")
+	rotate(t, "OPENAI_API_KEY", "sk-value-1111111111", "sk-value-2222222222")
+
+	require.NoError(t, executeCmd(t, "rollback", "OPENAI_API_KEY", "--to", "1"))
+
+	val, _ := testStore.Get("OPENAI_API_KEY")
+	assert.Equal(t, "sk-value-0000000000", val)
+
+	err := executeCmd(t, "rollback", "OPENAI_API_KEY", "--to", "9")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no version 9")
+}
+
+func TestRollback_NoHistory(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-value-0000000000")
+
+	err := executeCmd(t, "rollback", "OPENAI_API_KEY")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no previous values")
+}
+
+func TestRemove_MovesHistoryToTrash(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-value-0000000000")
+	rotate(t, "OPENAI_API_KEY", "sk-value-1111111111")
+	confirmWith(true)
+
+	require.NoError(t, executeCmd(t, "remove", "OPENAI_API_KEY"))
+
+	_, err := testStore.Get("history:OPENAI_API_KEY:1")
+	assert.Error(t, err)
+	old, err := testStore.Get("trash:history:OPENAI_API_KEY:1")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-value-0000000000", old)
+}
+
+func TestSet_OverlapExportsPrevious(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-old-value-123456")
+	cmd.SetReadPassword(func(_ string) (string, error) { return "sk-new-value-123456", nil })
+
+	require.NoError(t, executeCmd(t, "set", "OPENAI_API_KEY", "--overlap", "1h"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "env"))
+	})
+	assert.Contains(t, output, "export OPENAI_API_KEY=\"sk-new-value-123456\"")
+	assert.Contains(t, output, "export OPENAI_API_KEY_PREVIOUS=\"sk-old-value-123456\"")
+}
+
+func TestSet_PreviousFollowsPolicy(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-old-value-123456")
+	cmd.SetReadPassword(func(_ string) (string, error) { return "sk-new-value-123456", nil })
+	require.NoError(t, executeCmd(t, "set", "OPENAI_API_KEY", "--overlap", "1h"))
+	require.NoError(t, executeCmd(t, "policy", "add", "OPENAI_API_KEY", "python"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c", `printf %s "$OPENAI_API_KEY_PREVIOUS"`))
+	})
+	assert.Empty(t, output)
+}
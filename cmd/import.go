@@ -1,15 +1,33 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path"
+	"slices"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/rewrite"
 	"github.com/eazyhozy/sekret/internal/scanner"
 	"github.com/spf13/cobra"
 )
 
-var importFile string
+var (
+	importFile        string
+	importPlain       bool
+	importRewrite     bool
+	importRewriteMode string
+	importYes         bool
+	importOnly        []string
+	importExclude     []string
+	importOnConflict  string
+	importDryRun      bool
+	importJSON        bool
+)
 
 var importCmd = &cobra.Command{
 	Use:   "import",
@@ -19,16 +37,67 @@ var importCmd = &cobra.Command{
 By default, scans ~/.zshrc, ~/.zshenv, ~/.zprofile, ~/.bashrc,
 ~/.bash_profile, and ~/.profile.
 
-Use --file to import from a specific file instead.`,
+Use --file to import from a specific file instead.
+
+Use --plain to also offer non-secret exports (e.g. OPENAI_BASE_URL) for
+import as plain variables, stored in the config file.
+
+Use --rewrite to remove the imported exports from the files afterwards.
+Each line is commented out (with its value masked) or, with
+--rewrite-mode delete, deleted. The changes are shown as a diff for
+confirmation, and every file is backed up next to itself first.
+
+For scripted use, --yes imports every new key without asking (plain
+variables only when --only names them exactly), and --on-conflict
+decides what happens to keys already in sekret (skip, overwrite, or fail
+before changing anything; keys whose value is unchanged are always
+skipped). --only and --exclude filter env vars by glob, e.g. --only
+'OPENAI_*'. --dry-run prints the plan (with --json, as JSON) without
+importing anything.`,
 	Args: cobra.NoArgs,
 	RunE: runImport,
 }
 
 func init() {
 	importCmd.Flags().StringVar(&importFile, "file", "", "import from a specific file")
+	importCmd.Flags().BoolVar(&importPlain, "plain", false, "also offer non-secret exports as plain variables")
+	importCmd.Flags().BoolVar(&importRewrite, "rewrite", false, "remove imported exports from the scanned files")
+	importCmd.Flags().StringVar(&importRewriteMode, "rewrite-mode", "comment", "how --rewrite removes a line: comment or delete")
+	importCmd.Flags().BoolVarP(&importYes, "yes", "y", false, "import without asking (and apply --rewrite without confirmation)")
+	importCmd.Flags().StringSliceVar(&importOnly, "only", nil, "only import env vars matching these globs")
+	importCmd.Flags().StringSliceVar(&importExclude, "exclude", nil, "skip env vars matching these globs")
+	importCmd.Flags().StringVar(&importOnConflict, "on-conflict", "", "for keys already in sekret: skip, overwrite or fail (default: ask, or skip with --yes)")
+	importCmd.Flags().BoolVar(&importDryRun, "dry-run", false, "print the planned actions without importing")
+	importCmd.Flags().BoolVar(&importJSON, "json", false, "print the --dry-run plan as JSON")
 	rootCmd.AddCommand(importCmd)
 }
 
+// Planned import actions.
+const (
+	actionImport    = "import"
+	actionOverwrite = "overwrite"
+	actionSkip      = "skip"
+	actionFail      = "fail"
+	actionAsk       = "ask"
+)
+
+// importPlan is the action planned for a finding.
+type importPlan struct {
+	finding scanner.Finding
+	action  string
+	reason  string // why a finding is skipped or fails
+}
+
+// importPlanJSON is the --dry-run --json form of an importPlan.
+type importPlanJSON struct {
+	Action string `json:"action"`
+	EnvVar string `json:"env_var"`
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Plain  bool   `json:"plain,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
 // importResult tracks the outcome of a single finding during import.
 type importResult struct {
 	finding scanner.Finding
@@ -38,18 +107,30 @@ type importResult struct {
 
 func runImport(_ *cobra.Command, _ []string) error {
 	stderr := rootCmd.ErrOrStderr()
+	if err := validateImportFlags(); err != nil {
+		return err
+	}
 
 	paths, err := resolveImportTargets(importFile)
 	if err != nil {
 		return err
 	}
 
-	findings, err := scanner.ScanFiles(paths)
+	scan := scanner.ScanFiles
+	if importPlain {
+		scan = scanner.ScanFilesAll
+	}
+	findings, err := scan(paths)
 	if err != nil {
 		return err
 	}
+	findings = filterFindings(findings)
 
 	if len(findings) == 0 {
+		if importDryRun && importJSON {
+			fmt.Println("[]")
+			return nil
+		}
 		_, _ = fmt.Fprintln(stderr, "No exportable keys found.")
 		return nil
 	}
@@ -59,14 +140,35 @@ func runImport(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
+	plans := make([]importPlan, len(findings))
+	var failing []string
+	for i, f := range findings {
+		plans[i] = planImport(cfg, f)
+		if plans[i].action == actionFail {
+			failing = append(failing, f.EnvVar)
+		}
+	}
+	if importDryRun {
+		return printImportPlan(plans)
+	}
+	if len(failing) > 0 {
+		return fmt.Errorf("already registered in sekret: %s (--on-conflict fail); nothing was imported",
+			strings.Join(failing, ", "))
+	}
+
 	_, _ = fmt.Fprintf(stderr, "\nFound %d exportable %s:\n\n",
 		len(findings), pluralize(len(findings), "key", "keys"))
-	_, _ = fmt.Fprintln(stderr, "Import each key? (y: import / s: skip / q: quit)")
+	if slices.ContainsFunc(plans, func(p importPlan) bool { return p.action == actionAsk }) {
+		_, _ = fmt.Fprintln(stderr, "Import each key? (y: import / s: skip / q: quit)")
+	}
 
 	results := make([]importResult, 0, len(findings))
 
-	for i, f := range findings {
-		result, err := processImportFinding(stderr, cfg, f, i, len(findings))
+	for i, p := range plans {
+		// Re-plan against the keys imported so far: an env var may be
+		// exported in more than one file (e.g. ~/.zshrc and ~/.bashrc).
+		p = planImport(cfg, p.finding)
+		result, err := processImportFinding(stderr, cfg, p, i, len(plans))
 		if err != nil {
 			return err
 		}
@@ -75,27 +177,152 @@ func runImport(_ *cobra.Command, _ []string) error {
 
 		if result.status == "cancelled" {
 			// Mark remaining findings as cancelled too
-			for _, remaining := range findings[i+1:] {
-				results = append(results, importResult{finding: remaining, status: "cancelled"})
+			for _, remaining := range plans[i+1:] {
+				results = append(results, importResult{finding: remaining.finding, status: "cancelled"})
 			}
 			break
 		}
 	}
 
 	printImportSummary(results)
+	if importRewrite {
+		return rewriteImported(stderr, results)
+	}
 	return nil
 }
 
-// processImportFinding handles a single finding in the import loop.
+// validateImportFlags checks the values of the import flags.
+func validateImportFlags() error {
+	if importRewriteMode != "comment" && importRewriteMode != "delete" {
+		return fmt.Errorf("invalid --rewrite-mode %q: use comment or delete", importRewriteMode)
+	}
+	switch importOnConflict {
+	case "", actionSkip, actionOverwrite, actionFail:
+	default:
+		return fmt.Errorf("invalid --on-conflict %q: use skip, overwrite or fail", importOnConflict)
+	}
+	if importJSON && !importDryRun {
+		return fmt.Errorf("--json requires --dry-run")
+	}
+	for _, pattern := range append(slices.Clone(importOnly), importExclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// filterFindings applies --only and --exclude to findings.
+func filterFindings(findings []scanner.Finding) []scanner.Finding {
+	matches := func(patterns []string, envVar string) bool {
+		return slices.ContainsFunc(patterns, func(p string) bool {
+			ok, _ := path.Match(p, envVar)
+			return ok
+		})
+	}
+	return slices.DeleteFunc(findings, func(f scanner.Finding) bool {
+		return (len(importOnly) > 0 && !matches(importOnly, f.EnvVar)) || matches(importExclude, f.EnvVar)
+	})
+}
+
+// planImport decides what to do with a finding given the import flags.
+func planImport(cfg *config.Config, f scanner.Finding) importPlan {
+	p := importPlan{finding: f}
+	if multi := cfg.FindKeyByField(f.EnvVar); multi != nil {
+		p.action, p.reason = actionSkip, fmt.Sprintf("Registered as a field of %q", multi.EnvVar)
+		return p
+	}
+
+	existing := cfg.FindKeyByEnvVar(f.EnvVar)
+	switch {
+	case existing != nil && existing.IsDerived():
+		p.action, p.reason = actionSkip, "Registered as a derived variable"
+	case existing == nil && importYes && f.Plain && !slices.Contains(importOnly, f.EnvVar):
+		p.action, p.reason = actionSkip, "Plain variable (name it in --only to import it)"
+	case existing == nil && importYes:
+		p.action = actionImport
+	case existing == nil:
+		p.action = actionAsk
+	case importOnConflict != "" && sameValue(existing, f.Value):
+		p.action, p.reason = actionSkip, "Already in sekret with the same value"
+	case importOnConflict == actionOverwrite:
+		p.action = actionOverwrite
+	case importOnConflict == actionFail:
+		p.action, p.reason = actionFail, "Already registered in sekret"
+	case importOnConflict == actionSkip || importYes:
+		p.action, p.reason = actionSkip, "Already registered in sekret"
+	default:
+		p.action = actionAsk
+	}
+	return p
+}
+
+// sameValue reports whether existing already holds value.
+func sameValue(existing *config.KeyEntry, value string) bool {
+	if existing.IsPlain() {
+		return existing.Value == value
+	}
+	stored, err := store.Get(existing.KeychainKey())
+	return err == nil && stored == value
+}
+
+// printImportPlan prints the planned actions for --dry-run.
+func printImportPlan(plans []importPlan) error {
+	if importJSON {
+		out := make([]importPlanJSON, len(plans))
+		for i, p := range plans {
+			out[i] = importPlanJSON{Action: p.action, EnvVar: p.finding.EnvVar, File: p.finding.FilePath,
+				Line: p.finding.Line, Plain: p.finding.Plain, Reason: p.reason}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "Action\tEnv Variable\tValue\tSource\tNote")
+	_, _ = fmt.Fprintln(w, "------\t------------\t-----\t------\t----")
+	for _, p := range plans {
+		f := p.finding
+		display := displayMaskedValue(f.Value)
+		if f.Plain {
+			display = "plain: " + f.Value
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s:%d\t%s\n", p.action, f.EnvVar, display,
+			shortenHome(f.FilePath), f.Line, p.reason)
+	}
+	return w.Flush()
+}
+
+// processImportFinding carries out the plan for a single finding, asking
+// the user where the plan leaves it open.
 // Returns a fatal error only for config save failures.
-func processImportFinding(stderr interface{ Write([]byte) (int, error) }, cfg *config.Config, f scanner.Finding, index, total int) (importResult, error) {
+func processImportFinding(stderr interface{ Write([]byte) (int, error) }, cfg *config.Config, p importPlan, index, total int) (importResult, error) {
+	f := p.finding
 	displayPath := shortenHome(f.FilePath)
 
-	_, _ = fmt.Fprintf(stderr, "\n  [%d/%d] %s (%s)\n", index+1, total, f.EnvVar, displayMaskedValue(f.Value))
+	display := displayMaskedValue(f.Value)
+	if f.Plain {
+		display = "plain: " + f.Value
+	}
+	_, _ = fmt.Fprintf(stderr, "\n  [%d/%d] %s (%s)\n", index+1, total, f.EnvVar, display)
 	_, _ = fmt.Fprintf(stderr, "         %s:%d\n", displayPath, f.Line)
 
-	// Check if already registered in sekret
 	existing := cfg.FindKeyByEnvVar(f.EnvVar)
+	switch p.action {
+	case actionSkip:
+		_, _ = fmt.Fprintf(stderr, "         %s. Skipped\n", p.reason)
+		return importResult{finding: f, status: "skipped"}, nil
+	case actionImport:
+		return doImport(stderr, cfg, f)
+	case actionOverwrite:
+		return overwriteKey(stderr, cfg, f, existing)
+	case actionFail:
+		err := fmt.Errorf("%s (--on-conflict fail)", strings.ToLower(p.reason))
+		_, _ = fmt.Fprintf(stderr, "         %s. Failed\n", p.reason)
+		return importResult{finding: f, status: "failed", err: err}, nil
+	}
+
 	if existing != nil {
 		_, _ = fmt.Fprintf(stderr, "         Already registered in sekret.\n")
 		return handleOverwrite(stderr, cfg, f, existing)
@@ -113,13 +340,7 @@ func handleOverwrite(stderr interface{ Write([]byte) (int, error) }, cfg *config
 
 	switch strings.ToLower(strings.TrimSpace(choice)) {
 	case "y", "yes":
-		keychainKey := existing.KeychainKey()
-		if err := store.Set(keychainKey, f.Value); err != nil {
-			_, _ = fmt.Fprintf(stderr, "         Failed — %s\n", err)
-			return importResult{finding: f, status: "failed", err: err}, nil
-		}
-		_, _ = fmt.Fprintf(stderr, "         Overwritten %s\n", f.EnvVar)
-		return importResult{finding: f, status: "overwritten"}, nil
+		return overwriteKey(stderr, cfg, f, existing)
 	case "", "n", "no":
 		_, _ = fmt.Fprintf(stderr, "         Skipped\n")
 		return importResult{finding: f, status: "skipped"}, nil
@@ -129,14 +350,45 @@ func handleOverwrite(stderr interface{ Write([]byte) (int, error) }, cfg *config
 	}
 }
 
+// overwriteKey replaces the value of an existing key with a finding's.
+func overwriteKey(stderr interface{ Write([]byte) (int, error) }, cfg *config.Config, f scanner.Finding, existing *config.KeyEntry) (importResult, error) {
+	if existing.IsPlain() {
+		existing.Value = f.Value
+		if err := saveConfig(cfg); err != nil {
+			return importResult{}, fmt.Errorf("failed to save config: %w", err)
+		}
+		recordAudit("import", "", f.EnvVar)
+		_, _ = fmt.Fprintf(stderr, "         Overwritten %s\n", f.EnvVar)
+		return importResult{finding: f, status: "overwritten"}, nil
+	}
+	if err := replaceValue(cfg, existing, f.Value); err != nil {
+		_, _ = fmt.Fprintf(stderr, "         Failed — %s\n", err)
+		return importResult{finding: f, status: "failed", err: err}, nil
+	}
+	recordAudit("import", "", f.EnvVar)
+	_, _ = fmt.Fprintf(stderr, "         Overwritten %s\n", f.EnvVar)
+	return importResult{finding: f, status: "overwritten"}, nil
+}
+
 // handleNewKey prompts for import of a new key.
+// Plain (non-secret) findings default to skip, since most exports are not
+// worth moving.
 func handleNewKey(stderr interface{ Write([]byte) (int, error) }, cfg *config.Config, f scanner.Finding) (importResult, error) {
-	choice, err := readChoice("         Import? [Y/s/q]: ")
+	prompt := "         Import? [Y/s/q]: "
+	if f.Plain {
+		prompt = "         Import as plain variable? [y/S/q]: "
+	}
+	choice, err := readChoice(prompt)
 	if err != nil {
 		return importResult{}, fmt.Errorf("failed to read input: %w", err)
 	}
 
-	switch strings.ToLower(strings.TrimSpace(choice)) {
+	choice = strings.ToLower(strings.TrimSpace(choice))
+	if choice == "" && f.Plain {
+		choice = "s"
+	}
+
+	switch choice {
 	case "", "y", "yes":
 		return doImport(stderr, cfg, f)
 	case "s", "skip":
@@ -153,19 +405,43 @@ func handleNewKey(stderr interface{ Write([]byte) (int, error) }, cfg *config.Co
 }
 
 // doImport saves a finding to keychain and config.
+// Plain findings are stored in the config only.
 func doImport(stderr interface{ Write([]byte) (int, error) }, cfg *config.Config, f scanner.Finding) (importResult, error) {
-	if err := store.Set(f.EnvVar, f.Value); err != nil {
-		_, _ = fmt.Fprintf(stderr, "         Failed — %s\n", err)
-		return importResult{finding: f, status: "failed", err: err}, nil
+	entry := config.KeyEntry{EnvVar: f.EnvVar}
+	if f.Plain {
+		entry.Kind = config.KindPlain
+		entry.Value = f.Value
 	}
 
-	if err := cfg.AddKey("", f.EnvVar); err != nil {
-		return importResult{}, fmt.Errorf("failed to register key: %w", err)
+	var keychainErr error
+	added := false
+	err := transaction("import", func() error {
+		if !f.Plain {
+			if keychainErr = store.Set(f.EnvVar, f.Value); keychainErr != nil {
+				return keychainErr
+			}
+		}
+		if err := cfg.AddEntry(entry); err != nil {
+			return fmt.Errorf("failed to register key: %w", err)
+		}
+		added = true
+		if err := config.Save(cfg); err != nil {
+			return fmt.Errorf("failed to save config: %w", err)
+		}
+		return nil
+	})
+	if keychainErr != nil {
+		_, _ = fmt.Fprintf(stderr, "         Failed — %s\n", keychainErr)
+		return importResult{finding: f, status: "failed", err: keychainErr}, nil
 	}
-	if err := config.Save(cfg); err != nil {
-		return importResult{}, fmt.Errorf("failed to save config: %w", err)
+	if err != nil {
+		if added {
+			_ = cfg.RemoveKey(f.EnvVar) // rolled back on disk
+		}
+		return importResult{}, err
 	}
 
+	recordAudit("import", "", f.EnvVar)
 	_, _ = fmt.Fprintf(stderr, "         Imported %s\n", f.EnvVar)
 	return importResult{finding: f, status: "imported"}, nil
 }
@@ -242,8 +518,9 @@ func printImportSummary(results []importResult) {
 	}
 
 	// Advice to remove imported keys
-	if len(imported)+len(overwritten) > 0 {
-		fmt.Println("\nRemove the imported keys from your shell config (lines listed above).")
+	if len(imported)+len(overwritten) > 0 && !importRewrite {
+		fmt.Println("\nRemove the imported keys from your shell config (lines listed above),")
+		fmt.Println("or run 'sekret import --rewrite' next time to have them removed for you.")
 	}
 }
 
@@ -269,3 +546,121 @@ func resolveImportTargets(file string) ([]string, error) {
 	}
 	return targets, nil
 }
+
+// fileRewrite is a pending change to a scanned file.
+type fileRewrite struct {
+	path, before, after string
+}
+
+// rewriteImported removes the lines of imported findings from their files,
+// after showing the changes as a diff and asking for confirmation. Files
+// are backed up first, and left alone if a line changed since the scan.
+func rewriteImported(stderr interface{ Write([]byte) (int, error) }, results []importResult) error {
+	moved := map[string]string{} // env var -> imported value
+	for _, r := range results {
+		if r.status == "imported" || r.status == "overwritten" {
+			moved[r.finding.EnvVar] = r.finding.Value
+		}
+	}
+
+	var files []string
+	edits := map[string][]rewrite.Edit{}
+	masks := map[string][]rewrite.Edit{} // hide the values in the diff
+	for _, r := range results {
+		f := r.finding
+		// Duplicates of an imported export go too, if they hold its value.
+		if value, ok := moved[f.EnvVar]; !ok || value != f.Value || r.status == "cancelled" || r.status == "failed" {
+			continue
+		}
+		if edits[f.FilePath] == nil {
+			files = append(files, f.FilePath)
+		}
+		edits[f.FilePath] = append(edits[f.FilePath], rewrite.Edit{
+			Line:   f.Line,
+			Old:    f.Raw,
+			New:    commentOut(f),
+			Delete: importRewriteMode == "delete",
+		})
+		masks[f.FilePath] = append(masks[f.FilePath], rewrite.Edit{Line: f.Line, Old: f.Raw, New: maskFinding(f)})
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	var changes []fileRewrite
+	fmt.Println()
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		after, err := rewrite.Apply(string(data), edits[path])
+		if err != nil {
+			_, _ = fmt.Fprintf(stderr, "sekret: warning: not rewriting %s: %v\n", shortenHome(path), err)
+			continue
+		}
+		masked, err := rewrite.Apply(string(data), masks[path])
+		if err != nil {
+			return err
+		}
+		diff, err := rewrite.Diff(shortenHome(path), masked, after)
+		if err != nil {
+			return err
+		}
+		fmt.Print(diff)
+		changes = append(changes, fileRewrite{path: path, before: string(data), after: after})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	confirmed := importYes
+	if !confirmed {
+		var err error
+		confirmed, err = readConfirm(fmt.Sprintf("\n  Apply these changes to %d %s? [y/N]: ",
+			len(changes), pluralize(len(changes), "file", "files")))
+		if err != nil {
+			return err
+		}
+	}
+	if !confirmed {
+		_, _ = fmt.Fprintln(stderr, "  Cancelled")
+		return nil
+	}
+
+	now := time.Now()
+	for _, ch := range changes {
+		backup, err := rewrite.Backup(ch.path, now)
+		if err != nil {
+			return err
+		}
+		if err := rewrite.Write(ch.path, ch.before, ch.after); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(stderr, "  Rewrote %s (backup: %s)\n", shortenHome(ch.path), shortenHome(backup))
+	}
+	_, _ = fmt.Fprintln(stderr, "  The backups still contain the plaintext keys; delete them once you have checked the result.")
+	return nil
+}
+
+// commentOut returns the line of a finding commented out, with a secret
+// value masked so that it no longer appears in the file.
+func commentOut(f scanner.Finding) string {
+	line := maskFinding(f)
+	body, cr := strings.CutSuffix(line, "\r")
+	rest := strings.TrimLeft(body, " \t")
+	line = body[:len(body)-len(rest)] + "# " + rest + "  # moved to sekret"
+	if cr {
+		line += "\r"
+	}
+	return line
+}
+
+// maskFinding returns the line of a finding with a secret value masked.
+// Plain values are shown as they are.
+func maskFinding(f scanner.Finding) string {
+	if f.Plain || f.Value == "" {
+		return f.Raw
+	}
+	return strings.Replace(f.Raw, f.Value, scanner.MaskValue(f.Value), 1)
+}
@@ -2,6 +2,7 @@ package cmd_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -341,3 +342,316 @@ type failingStore struct {
 func (s *failingStore) Set(_ string, _ string) error {
 	return fmt.Errorf("keychain unavailable")
 }
+
+func TestImport_PlainVariables(t *testing.T) {
+	setup(t)
+	path := writeImportFile(t, `export OPENAI_API_KEY="sk-proj-abcdef1234"
+export OPENAI_BASE_URL="https://api.openai.com/v1"
+export EDITOR="vim"
+`)
+	cmd.SetReadChoice(choiceSequence("y", "y", "")) // import key, import base URL, default-skip EDITOR
+
+	stdout, stderr, err := executeImport(t, "--file", path, "--plain")
+	require.NoError(t, err)
+
+	assert.Contains(t, stderr, "plain: https://api.openai.com/v1")
+	assert.Contains(t, stdout, "2 imported")
+	assert.Contains(t, stdout, "1 skipped")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	entry := cfg.FindKeyByEnvVar("OPENAI_BASE_URL")
+	require.NotNil(t, entry)
+	assert.True(t, entry.IsPlain())
+	assert.Equal(t, "https://api.openai.com/v1", entry.Value)
+	assert.Nil(t, cfg.FindKeyByEnvVar("EDITOR"))
+
+	_, err = testStore.Get("OPENAI_BASE_URL")
+	assert.Error(t, err, "plain variables should not be stored in the keychain")
+}
+
+func TestImport_WithoutPlainFlagIgnoresPlainExports(t *testing.T) {
+	setup(t)
+	path := writeImportFile(t, `export OPENAI_BASE_URL="https://api.openai.com/v1"`)
+
+	_, stderr, err := executeImport(t, "--file", path)
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "No exportable keys found")
+}
+
+func TestImport_RewriteComment(t *testing.T) {
+	setup(t)
+	path := writeImportFile(t, "# keys\nexport OPENAI_API_KEY=\"sk-proj-abcdef1234\"\nexport GITHUB_TOKEN=\"ghp_abcdef1234567890\"\n")
+	cmd.SetReadChoice(choiceSequence("y", "s"))
+	confirmWith(true)
+
+	stdout, stderr, err := executeImport(t, "--file", path, "--rewrite")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "-export OPENAI_API_KEY=\"sk-proj-...1234\"\n")
+	assert.NotContains(t, stdout, "sk-proj-abcdef1234")
+	assert.Contains(t, stderr, "Rewrote")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# keys\n# export OPENAI_API_KEY=\"sk-proj-...1234\"  # moved to sekret\nexport GITHUB_TOKEN=\"ghp_abcdef1234567890\"\n", string(data))
+
+	backups, err := filepath.Glob(path + ".sekret-backup-*")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	data, err = os.ReadFile(backups[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "sk-proj-abcdef1234")
+}
+
+func TestImport_RewriteDelete(t *testing.T) {
+	setup(t)
+	path := writeImportFile(t, "export OPENAI_API_KEY=\"sk-proj-abcdef1234\"\nexport EDITOR=vim\n")
+	cmd.SetReadChoice(choiceSequence("y"))
+	confirmWith(true)
+
+	stdout, _, err := executeImport(t, "--file", path, "--rewrite", "--rewrite-mode", "delete")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "-export OPENAI_API_KEY=\"sk-proj-...1234\"\n")
+	assert.NotContains(t, stdout, "sk-proj-abcdef1234")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "export EDITOR=vim\n", string(data))
+}
+
+func TestImport_RewriteDeclined(t *testing.T) {
+	setup(t)
+	original := "export OPENAI_API_KEY=\"sk-proj-abcdef1234\"\n"
+	path := writeImportFile(t, original)
+	cmd.SetReadChoice(choiceSequence("y"))
+	confirmWith(false)
+
+	_, _, err := executeImport(t, "--file", path, "--rewrite")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+	backups, _ := filepath.Glob(path + ".sekret-backup-*")
+	assert.Empty(t, backups)
+}
+
+func TestImport_RewriteSkipsChangedFile(t *testing.T) {
+	setup(t)
+	path := writeImportFile(t, "export OPENAI_API_KEY=\"sk-proj-abcdef1234\"\n")
+	changed := "export OPENAI_API_KEY=\"sk-proj-edited5678\"\n"
+	cmd.SetReadChoice(func(_ string) (string, error) {
+		// The file is edited while sekret waits for input.
+		return "y", os.WriteFile(path, []byte(changed), 0o600)
+	})
+	prompts := confirmWith(true)
+
+	_, stderr, err := executeImport(t, "--file", path, "--rewrite")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "line 1 has changed since it was scanned")
+	assert.Zero(t, *prompts)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, changed, string(data))
+}
+
+func TestImport_InvalidRewriteMode(t *testing.T) {
+	setup(t)
+	path := writeImportFile(t, "export OPENAI_API_KEY=\"sk-proj-abcdef1234\"\n")
+
+	_, _, err := executeImport(t, "--file", path, "--rewrite", "--rewrite-mode", "shred")
+	assert.ErrorContains(t, err, "invalid --rewrite-mode")
+}
+
+func TestImport_YesWithFilters(t *testing.T) {
+	setup(t)
+	path := writeImportFile(t, `export OPENAI_API_KEY="sk-proj-abcdef1234"
+export OPENAI_ORG_KEY="org-abcdef1234"
+export GITHUB_TOKEN="ghp_abcdef1234567890"
+`)
+
+	_, _, err := executeImport(t, "--file", path, "--yes", "--only", "OPENAI_*", "--exclude", "*_ORG_KEY")
+	require.NoError(t, err)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	require.Len(t, cfg.Keys, 1)
+	assert.Equal(t, "OPENAI_API_KEY", cfg.Keys[0].EnvVar)
+}
+
+func TestImport_OnConflict(t *testing.T) {
+	content := "export OPENAI_API_KEY=\"sk-proj-new\"\nexport GITHUB_TOKEN=\"ghp_same\"\n"
+
+	t.Run("skip", func(t *testing.T) {
+		setup(t)
+		seedKey(t, "OPENAI_API_KEY", "sk-proj-old")
+		_, stderr, err := executeImport(t, "--file", writeImportFile(t, content), "--yes")
+		require.NoError(t, err)
+		assert.Contains(t, stderr, "Already registered in sekret. Skipped")
+		val, _ := testStore.Get("OPENAI_API_KEY")
+		assert.Equal(t, "sk-proj-old", val)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		setup(t)
+		seedKey(t, "OPENAI_API_KEY", "sk-proj-old")
+		_, _, err := executeImport(t, "--file", writeImportFile(t, content), "--yes", "--on-conflict", "overwrite")
+		require.NoError(t, err)
+		val, _ := testStore.Get("OPENAI_API_KEY")
+		assert.Equal(t, "sk-proj-new", val)
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		setup(t)
+		seedKey(t, "OPENAI_API_KEY", "sk-proj-old")
+		seedKey(t, "GITHUB_TOKEN", "ghp_same")
+		_, _, err := executeImport(t, "--file", writeImportFile(t, content), "--yes", "--on-conflict", "fail")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "already registered in sekret: OPENAI_API_KEY")
+		assert.NotContains(t, err.Error(), "GITHUB_TOKEN", "unchanged values are not conflicts")
+		val, _ := testStore.Get("OPENAI_API_KEY")
+		assert.Equal(t, "sk-proj-old", val)
+	})
+}
+
+func TestImport_DryRun(t *testing.T) {
+	setup(t)
+	seedKey(t, "GITHUB_TOKEN", "ghp_old")
+	path := writeImportFile(t, "export OPENAI_API_KEY=\"sk-proj-abcdef1234\"\nexport GITHUB_TOKEN=\"ghp_abcdef1234567890\"\n")
+
+	stdout, _, err := executeImport(t, "--file", path, "--dry-run", "--on-conflict", "overwrite")
+	require.NoError(t, err)
+	assert.Contains(t, stdout, "Action")
+	assert.Regexp(t, `ask\s+OPENAI_API_KEY\s+sk-proj-...1234`, stdout)
+	assert.Regexp(t, `overwrite\s+GITHUB_TOKEN`, stdout)
+	assert.NotContains(t, stdout, "abcdef1234567890")
+
+	stdout, _, err = executeImport(t, "--file", path, "--dry-run", "--json", "--yes")
+	require.NoError(t, err)
+	var plan []struct {
+		Action string `json:"action"`
+		EnvVar string `json:"env_var"`
+		Line   int    `json:"line"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &plan))
+	require.Len(t, plan, 2)
+	assert.Equal(t, "import", plan[0].Action)
+	assert.Equal(t, "skip", plan[1].Action)
+	assert.Equal(t, 2, plan[1].Line)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Len(t, cfg.Keys, 1, "dry run imports nothing")
+}
+
+func TestImport_InvalidFlags(t *testing.T) {
+	setup(t)
+	path := writeImportFile(t, "export OPENAI_API_KEY=\"sk-proj-abcdef1234\"\n")
+
+	_, _, err := executeImport(t, "--file", path, "--on-conflict", "merge")
+	assert.ErrorContains(t, err, "invalid --on-conflict")
+	_, _, err = executeImport(t, "--file", path, "--json")
+	assert.ErrorContains(t, err, "--json requires --dry-run")
+	_, _, err = executeImport(t, "--file", path, "--only", "[")
+	assert.ErrorContains(t, err, "invalid glob")
+}
+
+func TestImport_YesAppliesRewrite(t *testing.T) {
+	setup(t)
+	path := writeImportFile(t, "export OPENAI_API_KEY=\"sk-proj-abcdef1234\"\nexport EDITOR=vim\n")
+
+	_, _, err := executeImport(t, "--file", path, "--yes", "--rewrite", "--rewrite-mode", "delete")
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "export EDITOR=vim\n", string(data))
+}
+
+func TestImport_SameKeyInTwoFiles(t *testing.T) {
+	setup(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	zshrc := filepath.Join(home, ".zshrc")
+	bashrc := filepath.Join(home, ".bashrc")
+	require.NoError(t, os.WriteFile(zshrc, []byte("export OPENAI_API_KEY=\"sk-proj-abcdef1234\"\n"), 0o600))
+	require.NoError(t, os.WriteFile(bashrc, []byte("export OPENAI_API_KEY=\"sk-proj-abcdef1234\"\nexport GITHUB_TOKEN=\"ghp_abcdef1234567890\"\n"), 0o600))
+
+	stdout, stderr, err := executeImport(t, "--yes", "--rewrite", "--rewrite-mode", "delete")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "Already registered in sekret. Skipped")
+	assert.Contains(t, stdout, "Done. 2 imported, 1 skipped.")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Len(t, cfg.Keys, 2)
+	val, err := testStore.Get("OPENAI_API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-proj-abcdef1234", val)
+
+	data, err := os.ReadFile(zshrc)
+	require.NoError(t, err)
+	assert.Empty(t, string(data))
+	data, err = os.ReadFile(bashrc)
+	require.NoError(t, err)
+	assert.Empty(t, string(data), "the duplicate holds the imported value, so it is removed too")
+}
+
+func TestImport_SameKeyInTwoFilesFailsOnConflict(t *testing.T) {
+	setup(t)
+	path := writeImportFile(t, "export OPENAI_API_KEY=\"sk-proj-first1234\"\nexport OPENAI_API_KEY=\"sk-proj-second1234\"\n")
+
+	stdout, stderr, err := executeImport(t, "--file", path, "--yes", "--on-conflict", "fail")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "Already registered in sekret. Failed")
+	assert.Contains(t, stdout, "Done. 1 imported, 1 failed.")
+
+	val, err := testStore.Get("OPENAI_API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-proj-first1234", val)
+}
+
+func TestImport_SameKeyWithDifferentValues(t *testing.T) {
+	setup(t)
+	path := writeImportFile(t, "export OPENAI_API_KEY=\"sk-proj-first1234\"\nexport OPENAI_API_KEY=\"sk-proj-second1234\"\n")
+
+	_, stderr, err := executeImport(t, "--file", path, "--yes", "--rewrite", "--rewrite-mode", "delete")
+	require.NoError(t, err)
+	assert.Contains(t, stderr, "Already registered in sekret. Skipped")
+
+	val, err := testStore.Get("OPENAI_API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-proj-first1234", val)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "export OPENAI_API_KEY=\"sk-proj-second1234\"\n", string(data))
+}
+
+func TestImport_YesSkipsPlainUnlessNamed(t *testing.T) {
+	content := "export OPENAI_API_KEY=\"sk-proj-abcdef1234\"\nexport OPENAI_BASE_URL=\"https://api.openai.com/v1\"\nexport EDITOR=vim\n"
+
+	t.Run("glob", func(t *testing.T) {
+		setup(t)
+		_, stderr, err := executeImport(t, "--file", writeImportFile(t, content), "--yes", "--plain", "--only", "OPENAI_*")
+		require.NoError(t, err)
+		assert.Contains(t, stderr, "Plain variable (name it in --only to import it). Skipped")
+
+		cfg, err := config.Load()
+		require.NoError(t, err)
+		require.Len(t, cfg.Keys, 1)
+		assert.Equal(t, "OPENAI_API_KEY", cfg.Keys[0].EnvVar)
+	})
+
+	t.Run("named", func(t *testing.T) {
+		setup(t)
+		_, _, err := executeImport(t, "--file", writeImportFile(t, content), "--yes", "--plain", "--only", "OPENAI_*,OPENAI_BASE_URL")
+		require.NoError(t, err)
+
+		cfg, err := config.Load()
+		require.NoError(t, err)
+		require.Len(t, cfg.Keys, 2)
+		assert.Equal(t, "OPENAI_BASE_URL", cfg.Keys[1].EnvVar)
+		assert.True(t, cfg.Keys[1].IsPlain())
+	})
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/eazyhozy/sekret/internal/fsutil"
+	"github.com/eazyhozy/sekret/internal/scanner"
+	"github.com/spf13/cobra"
+)
+
+var initShell string
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Load sekret in your shell's rc file",
+	Long: `Add a marked block loading sekret's keys to the rc file of your login
+shell (~/.zshrc, ~/.bashrc or ~/.bash_profile on macOS, or fish's
+config.fish). Running it again changes nothing; 'sekret deinit' removes
+the block.
+
+If your shell config still exports plaintext keys, init offers to import
+them first.`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
+}
+
+var deinitCmd = &cobra.Command{
+	Use:   "deinit",
+	Short: "Remove the block added by 'sekret init' from your shell config",
+	Args:  cobra.NoArgs,
+	RunE:  runDeinit,
+}
+
+func init() {
+	initCmd.Flags().StringVar(&initShell, "shell", "", "shell to set up: zsh, bash or fish (default: your login shell)")
+	rootCmd.AddCommand(initCmd, deinitCmd)
+}
+
+// Markers delimiting the block written by init.
+const (
+	initBlockStart = "# >>> sekret >>>"
+	initBlockEnd   = "# <<< sekret <<<"
+)
+
+// loginShell returns the name of the user's login shell, from $SHELL.
+func loginShell() string {
+	return filepath.Base(os.Getenv("SHELL"))
+}
+
+// shellRCFile returns the rc file shell reads for interactive sessions.
+func shellRCFile(shell string) (string, error) {
+	if shell == "fish" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("could not determine home directory")
+		}
+		return filepath.Join(home, ".config", "fish", "config.fish"), nil
+	}
+
+	var name string
+	switch shell {
+	case "zsh":
+		name = ".zshrc"
+	case "bash":
+		// macOS terminals start login shells, which read .bash_profile.
+		name = ".bashrc"
+		if runtime.GOOS == "darwin" {
+			name = ".bash_profile"
+		}
+	default:
+		return "", fmt.Errorf("unsupported shell %q: use --shell zsh, bash or fish", shell)
+	}
+	for _, path := range scanner.DefaultTargets() {
+		if filepath.Base(path) == name {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("could not determine home directory")
+}
+
+// shellLoadLine returns the line loading sekret's keys in shell.
+func shellLoadLine(shell string) string {
+	if shell == "fish" {
+		return "sekret env --shell fish | source"
+	}
+	return `eval "$(sekret env)"`
+}
+
+// loadsSekret reports whether the shell file at path runs 'sekret env'.
+func loadsSekret(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "#") && strings.Contains(line, "sekret env") {
+			return true
+		}
+	}
+	return false
+}
+
+// findInitBlock returns the line range [start, end] of the init block in
+// lines, or -1s if there is none.
+func findInitBlock(lines []string) (int, int) {
+	start := -1
+	for i, line := range lines {
+		switch strings.TrimSpace(line) {
+		case initBlockStart:
+			start = i
+		case initBlockEnd:
+			if start >= 0 {
+				return start, i
+			}
+		}
+	}
+	return -1, -1
+}
+
+// writeShellFile replaces the shell file at path, keeping its permissions.
+// If path is a symlink, as in a dotfiles repo, its target is replaced.
+func writeShellFile(path, content string) error {
+	path, err := fsutil.ResolveSymlinks(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+	perm := os.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	} else if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := fsutil.WriteFileAtomic(path, []byte(content), perm); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+func runInit(c *cobra.Command, _ []string) error {
+	stderr := c.ErrOrStderr()
+	shell := initShell
+	if shell == "" {
+		shell = loginShell()
+	}
+	rc, err := shellRCFile(shell)
+	if err != nil {
+		return err
+	}
+
+	findings, err := scanner.ScanFiles(scanner.DefaultTargets())
+	if err != nil {
+		return err
+	}
+	if len(findings) > 0 {
+		confirmed, err := readConfirm(fmt.Sprintf("  Your shell config exports %d plaintext %s. Import %s into sekret first? [y/N]: ",
+			len(findings), pluralize(len(findings), "key", "keys"), pluralize(len(findings), "it", "them")))
+		if err != nil {
+			return err
+		}
+		if confirmed {
+			if err := runImport(c, nil); err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintln(stderr)
+		}
+	}
+
+	data, err := os.ReadFile(rc)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", rc, err)
+	}
+	content := string(data)
+	if start, _ := findInitBlock(strings.Split(content, "\n")); start >= 0 {
+		_, _ = fmt.Fprintf(stderr, "  sekret is already set up in %s\n", shortenHome(rc))
+		return nil
+	}
+	if loadsSekret(rc) {
+		_, _ = fmt.Fprintf(stderr, "  %s already runs 'sekret env'; left unchanged\n", shortenHome(rc))
+		return nil
+	}
+
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if content != "" {
+		content += "\n"
+	}
+	content += fmt.Sprintf("%s\n# Added by 'sekret init'; remove with 'sekret deinit'.\n%s\n%s\n",
+		initBlockStart, shellLoadLine(shell), initBlockEnd)
+	if err := writeShellFile(rc, content); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(stderr, "  Added sekret to %s\n", shortenHome(rc))
+	_, _ = fmt.Fprintf(stderr, "  Open a new shell or run: source %s\n", shortenHome(rc))
+	return nil
+}
+
+func runDeinit(c *cobra.Command, _ []string) error {
+	paths := scanner.DefaultTargets()
+	if fish, err := shellRCFile("fish"); err == nil {
+		paths = append(paths, fish)
+	}
+
+	removed := 0
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		lines := strings.Split(string(data), "\n")
+		start, end := findInitBlock(lines)
+		if start < 0 {
+			continue
+		}
+		// Drop the blank line init put before the block, too.
+		if start > 0 && strings.TrimSpace(lines[start-1]) == "" {
+			start--
+		}
+		lines = append(lines[:start], lines[end+1:]...)
+		if err := writeShellFile(path, strings.Join(lines, "\n")); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintf(c.ErrOrStderr(), "  Removed sekret from %s\n", shortenHome(path))
+		removed++
+	}
+
+	if removed == 0 {
+		_, _ = fmt.Fprintln(c.ErrOrStderr(), "sekret is not set up in any shell config.")
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupInit(t *testing.T, shell string) string {
+	t.Helper()
+	setup(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("SHELL", "/bin/"+shell)
+	return home
+}
+
+func TestInit_AddsBlockOnce(t *testing.T) {
+	home := setupInit(t, "zsh")
+	rc := filepath.Join(home, ".zshrc")
+	original := "export PATH=\"$HOME/bin:$PATH\"\n"
+	require.NoError(t, os.WriteFile(rc, []byte(original), 0o600))
+
+	require.NoError(t, executeCmd(t, "init"))
+	require.NoError(t, executeCmd(t, "init"))
+
+	data, err := os.ReadFile(rc)
+	require.NoError(t, err)
+	assert.Equal(t, original+"\n# >>> sekret >>>\n# Added by 'sekret init'; remove with 'sekret deinit'.\n"+
+		"eval \"$(sekret env)\"\n# <<< sekret <<<\n", string(data))
+	info, err := os.Stat(rc)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm(), "permissions are kept")
+
+	require.NoError(t, executeCmd(t, "deinit"))
+	data, err = os.ReadFile(rc)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+}
+
+func TestInit_Fish(t *testing.T) {
+	home := setupInit(t, "zsh")
+
+	require.NoError(t, executeCmd(t, "init", "--shell", "fish"))
+
+	data, err := os.ReadFile(filepath.Join(home, ".config", "fish", "config.fish"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "sekret env --shell fish | source\n")
+	assert.NoFileExists(t, filepath.Join(home, ".zshrc"))
+}
+
+func TestInit_Bash(t *testing.T) {
+	home := setupInit(t, "bash")
+
+	require.NoError(t, executeCmd(t, "init"))
+
+	rc := ".bashrc"
+	if runtime.GOOS == "darwin" {
+		rc = ".bash_profile"
+	}
+	assert.FileExists(t, filepath.Join(home, rc))
+}
+
+func TestInit_UnsupportedShell(t *testing.T) {
+	setupInit(t, "tcsh")
+
+	err := executeCmd(t, "init")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported shell")
+}
+
+func TestInit_LeavesExistingEvalAlone(t *testing.T) {
+	home := setupInit(t, "zsh")
+	rc := filepath.Join(home, ".zshrc")
+	original := "eval \"$(sekret env)\"\n"
+	require.NoError(t, os.WriteFile(rc, []byte(original), 0o600))
+
+	require.NoError(t, executeCmd(t, "init"))
+
+	data, err := os.ReadFile(rc)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+}
+
+func TestInit_OffersImport(t *testing.T) {
+	home := setupInit(t, "zsh")
+	rc := filepath.Join(home, ".zshrc")
+	require.NoError(t, os.WriteFile(rc, []byte("export OPENAI_API_KEY=\"sk-proj-abc123\"\n"), 0o600))
+	prompts := confirmWith(false)
+
+	require.NoError(t, executeCmd(t, "init"))
+
+	assert.Equal(t, 1, *prompts)
+	data, err := os.ReadFile(rc)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# >>> sekret >>>")
+}
+
+func TestDeinit_NothingToRemove(t *testing.T) {
+	setupInit(t, "zsh")
+	require.NoError(t, executeCmd(t, "deinit"))
+}
+
+func TestInit_KeepsSymlinkedRCFile(t *testing.T) {
+	home := setupInit(t, "zsh")
+	target := filepath.Join(home, "dotfiles", "zshrc")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0o755))
+	original := "export PATH=\"$HOME/bin:$PATH\"\n"
+	require.NoError(t, os.WriteFile(target, []byte(original), 0o644))
+	rc := filepath.Join(home, ".zshrc")
+	require.NoError(t, os.Symlink(target, rc))
+
+	require.NoError(t, executeCmd(t, "init"))
+	info, err := os.Lstat(rc)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode()&os.ModeSymlink, "the link should be kept")
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# >>> sekret >>>")
+
+	require.NoError(t, executeCmd(t, "deinit"))
+	info, err = os.Lstat(rc)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode()&os.ModeSymlink, "the link should be kept")
+	data, err = os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"text/template"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/fsutil"
+	"github.com/spf13/cobra"
+)
+
+var (
+	injectInput        string
+	injectOutput       string
+	injectCleanupAfter bool
+)
+
+var injectCmd = &cobra.Command{
+	Use:   "inject -i <template> [-o <output>] [--cleanup-after -- <command> [args...]]",
+	Short: "Render a config file template with registered keys",
+	Long: `Render a Go text/template, filling {{ sekret "ENV_VAR" }} placeholders
+with registered key values. Unknown or unreadable keys fail the render.
+
+  # config.tmpl
+  api_key: {{ sekret "OPENAI_API_KEY" }}
+
+  sekret inject -i config.tmpl -o config.yaml
+
+The output file is created with 0600 permissions and written atomically.
+Without -o, the result is printed to stdout.
+
+With --cleanup-after, the given command is run once the file is written,
+and the file is deleted when the command exits:
+
+  sekret inject -i config.tmpl -o config.yaml --cleanup-after -- mytool -c config.yaml`,
+	RunE: runInject,
+}
+
+func init() {
+	injectCmd.Flags().StringVarP(&injectInput, "input", "i", "", "template file to render")
+	injectCmd.Flags().StringVarP(&injectOutput, "output", "o", "", "file to write (default: stdout)")
+	injectCmd.Flags().BoolVar(&injectCleanupAfter, "cleanup-after", false, "run the command after -- and delete the output file when it exits")
+	_ = injectCmd.MarkFlagRequired("input")
+	rootCmd.AddCommand(injectCmd)
+}
+
+func runInject(c *cobra.Command, args []string) error {
+	if injectCleanupAfter {
+		if injectOutput == "" {
+			return fmt.Errorf("--cleanup-after requires --output")
+		}
+		if len(args) == 0 {
+			return fmt.Errorf("--cleanup-after requires a command after --")
+		}
+	} else if len(args) > 0 {
+		return fmt.Errorf("unexpected arguments %q (use --cleanup-after to run a command)", args)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	tmplText, err := os.ReadFile(injectInput)
+	if err != nil {
+		return fmt.Errorf("failed to read template: %w", err)
+	}
+
+	rendered, keys, err := renderTemplate(cfg, injectInput, string(tmplText))
+	if err != nil {
+		return err
+	}
+	target := injectOutput
+	if injectCleanupAfter {
+		target = args[0]
+	}
+	recordAudit("read", target, keys...)
+
+	if injectOutput == "" {
+		_, err := os.Stdout.Write(rendered)
+		return err
+	}
+
+	if err := fsutil.WriteFileAtomic(injectOutput, rendered, 0o600); err != nil {
+		return err
+	}
+
+	if !injectCleanupAfter {
+		_, _ = fmt.Fprintf(c.ErrOrStderr(), "  Rendered %s\n", injectOutput)
+		return nil
+	}
+
+	code, runErr := runChild(args, os.Environ(), os.Stdout, os.Stderr)
+	if err := os.Remove(injectOutput); err != nil && !os.IsNotExist(err) {
+		_, _ = fmt.Fprintf(c.ErrOrStderr(), "sekret: warning: could not remove %s: %v\n", injectOutput, err)
+	}
+	if runErr != nil {
+		return runErr
+	}
+	if code != 0 {
+		exitFunc(code)
+	}
+	return nil
+}
+
+// renderTemplate executes a template whose sekret function returns key values.
+// Missing keys and map fields are errors rather than empty strings.
+// The secret keys the template read are returned for the audit log.
+func renderTemplate(cfg *config.Config, name, text string) ([]byte, []string, error) {
+	var used []keyValue
+	funcs := template.FuncMap{
+		"sekret": func(arg string) (string, error) {
+			value, err := readKeyValue(cfg, arg)
+			key := namedKey(cfg, arg)
+			if err == nil && !slices.Contains(used, key) {
+				used = append(used, key)
+			}
+			return value, err
+		},
+	}
+
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, nil); err != nil {
+		return nil, nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.Bytes(), auditKeys(used), nil
+}
@@ -0,0 +1,84 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTemplate(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.tmpl")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestInject_WritesOutputFile(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+	tmpl := writeTemplate(t, `api_key: {{ sekret "OPENAI_API_KEY" }}`+"\n")
+	out := filepath.Join(t.TempDir(), "config.yaml")
+
+	require.NoError(t, executeCmd(t, "inject", "-i", tmpl, "-o", out))
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "api_key: sk-test123\n", string(data))
+
+	info, err := os.Stat(out)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestInject_Stdout(t *testing.T) {
+	setup(t)
+	seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+	tmpl := writeTemplate(t, `token={{ sekret "github" }}`)
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "inject", "-i", tmpl))
+	})
+
+	assert.Equal(t, "token=ghp_abc123", output)
+}
+
+func TestInject_UnknownKeyFails(t *testing.T) {
+	setup(t)
+	tmpl := writeTemplate(t, `api_key: {{ sekret "MISSING_KEY" }}`)
+	out := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := executeCmd(t, "inject", "-i", tmpl, "-o", out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not registered")
+
+	_, statErr := os.Stat(out)
+	assert.True(t, os.IsNotExist(statErr), "output should not be written on failure")
+}
+
+func TestInject_CleanupAfter(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+	tmpl := writeTemplate(t, `{{ sekret "OPENAI_API_KEY" }}`)
+	out := filepath.Join(t.TempDir(), "config.yaml")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "inject", "-i", tmpl, "-o", out, "--cleanup-after", "--", "cat", out))
+	})
+
+	assert.Equal(t, "sk-test123", output)
+	_, err := os.Stat(out)
+	assert.True(t, os.IsNotExist(err), "output should be removed after the command exits")
+}
+
+func TestInject_CleanupAfterRequiresCommand(t *testing.T) {
+	setup(t)
+	tmpl := writeTemplate(t, `static`)
+	out := filepath.Join(t.TempDir(), "config.yaml")
+
+	err := executeCmd(t, "inject", "-i", tmpl, "-o", out, "--cleanup-after")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "requires a command")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/journal"
+)
+
+// transaction runs fn, which writes the keychain through store and then
+// saves the config, as a journaled operation: if fn fails or sekret dies
+// midway, its keychain writes and config changes are rolled back, now or
+// on the next invocation. Nested calls join the outer transaction.
+//
+// The config lock is held throughout, so fn must not prompt.
+func transaction(op string, fn func() error) error {
+	if _, ok := store.(*journal.Tx); ok {
+		return fn()
+	}
+	return withConfigLock(func() error { return runTransaction(op, fn) })
+}
+
+func runTransaction(op string, fn func() error) error {
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	tx, err := journal.Begin(store, path, op)
+	if err != nil {
+		return err
+	}
+
+	base := store
+	store = tx
+	err = fn()
+	store = base
+
+	if err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed, will retry on next run: %v)", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+// recoverJournal finishes or rolls back an operation left behind by an
+// interrupted run, reporting to w.
+func recoverJournal(w io.Writer) {
+	path, err := config.Path()
+	if err == nil {
+		var op string
+		var rolledBack bool
+		op, rolledBack, err = journal.Recover(store, path)
+		if err == nil && rolledBack {
+			_, _ = fmt.Fprintf(w, "sekret: note: rolled back an interrupted %q\n", op)
+		}
+	}
+	if err != nil {
+		_, _ = fmt.Fprintf(w, "sekret: warning: could not recover interrupted operation: %v\n", err)
+	}
+}
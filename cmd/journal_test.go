@@ -0,0 +1,97 @@
+package cmd_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/eazyhozy/sekret/cmd"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/journal"
+	"github.com/eazyhozy/sekret/internal/keychain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// faultyStore routes the commands through a fault-injecting store.
+func faultyStore(t *testing.T) *keychain.FaultStore {
+	t.Helper()
+	faults := keychain.NewFaultStore(testStore)
+	cmd.SetStore(faults)
+	return faults
+}
+
+func journalPath(t *testing.T) string {
+	t.Helper()
+	dir, err := config.Dir()
+	require.NoError(t, err)
+	return filepath.Join(dir, journal.FileName)
+}
+
+func TestSet_RollsBackOnKeychainFailure(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-old-value-123456")
+	faults := faultyStore(t)
+	cmd.SetReadPassword(func(_ string) (string, error) { return "sk-new-value-123456", nil })
+
+	// The history copy and the backup succeed; writing the new value fails.
+	faults.FailOnceAfter(2)
+	require.Error(t, executeCmd(t, "set", "OPENAI_API_KEY"))
+
+	val, _ := testStore.Get("OPENAI_API_KEY")
+	assert.Equal(t, "sk-old-value-123456", val)
+	_, err := testStore.Get("history:OPENAI_API_KEY:1")
+	assert.Error(t, err, "history copy should be rolled back")
+
+	cfg, _ := config.Load()
+	assert.Empty(t, cfg.FindKeyByEnvVar("OPENAI_API_KEY").History)
+	assert.NoFileExists(t, journalPath(t))
+}
+
+func TestAdd_RollsBackWhenConfigCannotBeUpdated(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-registered")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	// A multi-field entry claiming the env var makes registration fail
+	// after the value was already written.
+	require.NoError(t, cfg.AddEntry(config.KeyEntry{EnvVar: "creds", Kind: config.KindMulti, Fields: []string{"GITHUB_TOKEN"}}))
+	require.NoError(t, config.Save(cfg))
+	cmd.SetReadPassword(func(_ string) (string, error) { return "ghp_value", nil })
+
+	require.Error(t, executeCmd(t, "add", "GITHUB_TOKEN"))
+
+	_, err = testStore.Get("GITHUB_TOKEN")
+	assert.Error(t, err, "value should not be left behind without a config entry")
+}
+
+func TestRemove_RecoversInterruptedOperation(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-to-delete")
+	confirmWith(true)
+	faults := faultyStore(t)
+
+	// Moving the value to the trash fails when deleting the original, and
+	// the keychain keeps failing, so the rollback cannot finish either.
+	faults.FailAfter(2)
+	err := executeCmd(t, "remove", "OPENAI_API_KEY")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rollback failed")
+	assert.FileExists(t, journalPath(t))
+
+	// The next invocation rolls the operation back.
+	faults.FailAfter(-1)
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "list"))
+	})
+
+	val, err := testStore.Get("OPENAI_API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-to-delete", val)
+	_, err = testStore.Get("trash:OPENAI_API_KEY")
+	assert.Error(t, err)
+
+	cfg, _ := config.Load()
+	assert.NotNil(t, cfg.FindKeyByEnvVar("OPENAI_API_KEY"))
+	assert.Empty(t, cfg.Trash)
+	assert.NoFileExists(t, journalPath(t))
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var migrateLegacyCmd = &cobra.Command{
+	Use:   "migrate-legacy",
+	Short: "Re-key keys added before v0.2 under their env var name",
+	Long: `Keys added before v0.2 keep their keychain item under a short name (e.g.
+"openai") instead of their env var. This copies each such item to its env var
+name, verifies the copy and then forgets the short name.
+
+The config upgrade to version 2 does this automatically; run it by hand for
+keys the upgrade could not migrate.`,
+	Args: cobra.NoArgs,
+	RunE: runMigrateLegacy,
+}
+
+func init() {
+	rootCmd.AddCommand(migrateLegacyCmd)
+
+	config.RekeyLegacy = func(entry config.KeyEntry, trashed bool) (func(), error) {
+		stale, err := rekeyLegacy(entry, trashed)
+		if err != nil {
+			_, _ = fmt.Fprintf(rootCmd.ErrOrStderr(), "sekret: warning: could not re-key %s: %v (run 'sekret migrate-legacy' later)\n", entry.EnvVar, err)
+			return nil, err
+		}
+		return func() {
+			for _, item := range stale {
+				_ = store.Delete(item)
+			}
+		}, nil
+	}
+}
+
+// rekeyLegacy copies the keychain items of a legacy entry to the names they
+// have without Name, reading each copy back to verify it. It returns the
+// old items, which the caller deletes once the config no longer refers to
+// them. Items that are gone (e.g. because a config from before the
+// migration was restored) are skipped if their copy exists; missing
+// history items are skipped.
+func rekeyLegacy(entry config.KeyEntry, trashed bool) ([]string, error) {
+	item := func(name string) string { return name }
+	if trashed {
+		item = config.TrashItem
+	}
+	renamed := entry
+	renamed.Name = ""
+
+	var stale []string
+	if entry.InKeychain() && entry.KeychainKey() != renamed.KeychainKey() && !rekeyed(item(entry.KeychainKey()), item(renamed.KeychainKey())) {
+		from := item(entry.KeychainKey())
+		if err := copyItem(from, item(renamed.KeychainKey())); err != nil {
+			return nil, err
+		}
+		stale = append(stale, from)
+	}
+	for _, v := range entry.History {
+		from := item(entry.HistoryKey(v.Seq))
+		if _, err := store.Get(from); err != nil {
+			continue
+		}
+		if err := copyItem(from, item(renamed.HistoryKey(v.Seq))); err != nil {
+			return nil, err
+		}
+		stale = append(stale, from)
+	}
+	return stale, nil
+}
+
+// rekeyed reports whether keychain item from was already moved to to: from
+// is gone and to exists.
+func rekeyed(from, to string) bool {
+	if _, err := store.Get(from); err == nil {
+		return false
+	}
+	_, err := store.Get(to)
+	return err == nil
+}
+
+// copyItem copies keychain item from to to and verifies the copy. It
+// refuses to overwrite a different value already stored under to.
+func copyItem(from, to string) error {
+	value, err := store.Get(from)
+	if err != nil {
+		return err
+	}
+	if existing, err := store.Get(to); err == nil && existing != value {
+		return fmt.Errorf("keychain item %q already holds a different value", to)
+	}
+	if err := store.Set(to, value); err != nil {
+		return err
+	}
+	if got, err := store.Get(to); err != nil || got != value {
+		return fmt.Errorf("keychain item %q did not read back as written", to)
+	}
+	return nil
+}
+
+func runMigrateLegacy(c *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	var entries []*config.KeyEntry
+	var trashed []bool
+	for i := range cfg.Keys {
+		if cfg.Keys[i].Name != "" {
+			entries, trashed = append(entries, &cfg.Keys[i]), append(trashed, false)
+		}
+	}
+	for i := range cfg.Trash {
+		if cfg.Trash[i].Entry.Name != "" {
+			entries, trashed = append(entries, &cfg.Trash[i].Entry), append(trashed, true)
+		}
+	}
+	if len(entries) == 0 {
+		_, _ = fmt.Fprintln(c.ErrOrStderr(), "No legacy keys.")
+		return nil
+	}
+
+	var names []string
+	err = transaction("migrate-legacy", func() error {
+		for i, entry := range entries {
+			stale, err := rekeyLegacy(*entry, trashed[i])
+			if err != nil {
+				return fmt.Errorf("failed to re-key %s: %w", entry.EnvVar, err)
+			}
+			for _, item := range stale {
+				if err := store.Delete(item); err != nil {
+					return err
+				}
+			}
+			names = append(names, entry.Name)
+			entry.Name = ""
+		}
+		return config.Save(cfg)
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, entry := range entries {
+		_, _ = fmt.Fprintf(c.ErrOrStderr(), "  %s -> %s\n", names[i], entry.EnvVar)
+	}
+	_, _ = fmt.Fprintf(c.ErrOrStderr(), "  Re-keyed %d legacy key(s)\n", len(entries))
+	return nil
+}
@@ -0,0 +1,106 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateLegacy(t *testing.T) {
+	setup(t)
+	seedLegacyKey(t, "openai", "OPENAI_API_KEY", "sk-legacy")
+	seedKey(t, "GITHUB_TOKEN", "ghp_new")
+
+	require.NoError(t, executeCmd(t, "migrate-legacy"))
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.FindKeyByEnvVar("OPENAI_API_KEY").Name)
+	val, err := testStore.Get("OPENAI_API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-legacy", val)
+	_, err = testStore.Get("openai")
+	assert.Error(t, err, "old item should be deleted")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "OPENAI_API_KEY"))
+	})
+	assert.Contains(t, output, "sk-legacy")
+}
+
+func TestMigrateLegacy_Conflict(t *testing.T) {
+	setup(t)
+	seedLegacyKey(t, "openai", "OPENAI_API_KEY", "sk-legacy")
+	require.NoError(t, testStore.Set("OPENAI_API_KEY", "sk-other"))
+
+	err := executeCmd(t, "migrate-legacy")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already holds a different value")
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "openai", cfg.FindKeyByEnvVar("OPENAI_API_KEY").Name)
+	val, _ := testStore.Get("openai")
+	assert.Equal(t, "sk-legacy", val)
+}
+
+func TestConfigUpgrade_RekeysLegacyKeys(t *testing.T) {
+	setup(t)
+	dir, err := config.Dir()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"version":1,"keys":[
+		{"name":"openai","env_var":"OPENAI_API_KEY","history":[{"seq":1}]}
+	]}`), 0o600))
+	require.NoError(t, testStore.Set("openai", "sk-current"))
+	require.NoError(t, testStore.Set("history:openai:1", "sk-old"))
+
+	require.NoError(t, executeCmd(t, "list"))
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Version)
+	assert.Empty(t, cfg.FindKeyByEnvVar("OPENAI_API_KEY").Name)
+	val, _ := testStore.Get("OPENAI_API_KEY")
+	assert.Equal(t, "sk-current", val)
+	val, _ = testStore.Get("history:OPENAI_API_KEY:1")
+	assert.Equal(t, "sk-old", val)
+	_, err = testStore.Get("openai")
+	assert.Error(t, err)
+	_, err = testStore.Get("history:openai:1")
+	assert.Error(t, err)
+
+	backups, err := config.Backups()
+	require.NoError(t, err)
+	assert.NotEmpty(t, backups, "the version 1 config is kept")
+}
+
+func TestConfigRestore_PreMigrationBackup(t *testing.T) {
+	setup(t)
+	dir, err := config.Dir()
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(dir, 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"version":1,"keys":[
+		{"name":"openai","env_var":"OPENAI_API_KEY"}
+	]}`), 0o600))
+	require.NoError(t, testStore.Set("openai", "sk-current"))
+	require.NoError(t, executeCmd(t, "list"))
+
+	// Restore the version 1 config the upgrade backed up; its item is gone.
+	confirmWith(true)
+	require.NoError(t, executeCmd(t, "config", "restore"))
+	require.NoError(t, executeCmd(t, "list"))
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Version)
+	assert.Empty(t, cfg.FindKeyByEnvVar("OPENAI_API_KEY").Name)
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "OPENAI_API_KEY"))
+	})
+	assert.Contains(t, output, "sk-current")
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/dustin/go-humanize"
@@ -39,8 +40,18 @@ func runList(_ *cobra.Command, _ []string) error {
 
 	for _, k := range cfg.Keys {
 		preview := "(unavailable)"
-		if val, err := store.Get(k.KeychainKey()); err == nil {
-			preview = scanner.MaskValue(val)
+		if k.IsPlain() {
+			preview = k.Value
+		} else if k.IsMulti() {
+			preview = "(" + strings.Join(k.Fields, ", ") + ")"
+		} else if k.IsDerived() {
+			preview = "= " + k.Template
+		} else if val, err := store.Get(k.KeychainKey()); err == nil {
+			if k.IsFile() {
+				preview = fmt.Sprintf("(file, %s)", humanize.Bytes(uint64(len(val))))
+			} else {
+				preview = scanner.MaskValue(val)
+			}
 		}
 		added := humanize.Time(k.AddedAt)
 		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", k.EnvVar, preview, added)
@@ -38,3 +38,14 @@ func TestList_NoNameColumn(t *testing.T) {
 	assert.False(t, strings.HasPrefix(strings.TrimSpace(lines[0]), "Name"))
 	assert.True(t, strings.HasPrefix(strings.TrimSpace(lines[0]), "Env Variable"))
 }
+
+func TestList_PlainUnmasked(t *testing.T) {
+	setup(t)
+	require.NoError(t, executeCmd(t, "add", "ANTHROPIC_MODEL", "--plain", "claude-sonnet-long-name"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "list"))
+	})
+
+	assert.Contains(t, output, "claude-sonnet-long-name")
+}
@@ -0,0 +1,312 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/mcp"
+	"github.com/eazyhozy/sekret/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// mcpDefaultTimeout bounds run_command calls that don't set a timeout.
+	mcpDefaultTimeout = 2 * time.Minute
+	// mcpMaxOutput caps the command output returned to the client.
+	mcpMaxOutput = 256 * 1024
+	// mcpWaitDelay bounds how long run_command waits for the output pipes
+	// to close once the command has exited or timed out, in case a
+	// background process it started still holds them.
+	mcpWaitDelay = 2 * time.Second
+)
+
+var mcpAllowKeys []string
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Serve registered keys to AI tools over the Model Context Protocol",
+	Long: `Run an MCP server on stdio so AI coding tools can use registered keys
+without reading them. Configure it in your MCP client as:
+
+  { "mcpServers": { "sekret": { "command": "sekret", "args": ["mcp"] } } }
+
+Two tools are exposed:
+
+  list_keys    names and kinds of registered keys (never their values)
+  run_command  run a command with selected keys injected; key values are
+               masked in the output returned to the model
+
+Every run_command call must be approved at the terminal, unless it matches
+a rule added with 'sekret mcp allow'.`,
+	Args: cobra.NoArgs,
+	RunE: runMCP,
+}
+
+var mcpAllowCmd = &cobra.Command{
+	Use:   "allow <COMMAND_PATTERN> --keys <ENV_VAR>[,...]",
+	Short: "Pre-approve MCP run_command calls matching a pattern",
+	Long: `Allow MCP clients to run commands matching COMMAND_PATTERN with the given
+keys without asking. The pattern is matched against the full command line;
+* matches anything. Key names may also use *.
+
+  sekret mcp allow 'npm test*' --keys NPM_TOKEN
+  sekret mcp allow 'python scripts/*' --keys 'OPENAI_*'`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMCPAllow,
+}
+
+func init() {
+	mcpAllowCmd.Flags().StringSliceVar(&mcpAllowKeys, "keys", nil, "env vars the command may receive")
+	_ = mcpAllowCmd.MarkFlagRequired("keys")
+	mcpCmd.AddCommand(mcpAllowCmd)
+	rootCmd.AddCommand(mcpCmd)
+}
+
+func runMCP(c *cobra.Command, _ []string) error {
+	s := mcp.NewServer("sekret", version)
+	s.AddTool(mcp.Tool{
+		Name:        "list_keys",
+		Description: "List the env vars registered in sekret. Values are never returned.",
+		InputSchema: map[string]any{"type": "object", "properties": map[string]any{}},
+		Handler:     mcpListKeys,
+	})
+	s.AddTool(mcp.Tool{
+		Name: "run_command",
+		Description: "Run a command with the selected sekret keys injected as env vars. " +
+			"The user must approve the call. Key values are masked in the returned output.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"command":         map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "program and arguments"},
+				"keys":            map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": "env vars to inject"},
+				"cwd":             map[string]any{"type": "string", "description": "working directory"},
+				"timeout_seconds": map[string]any{"type": "integer", "description": "defaults to 120"},
+			},
+			"required": []string{"command", "keys"},
+		},
+		Handler: mcpRunCommand,
+	})
+	return s.Serve(c.InOrStdin(), c.OutOrStdout())
+}
+
+// mcpKey is the metadata list_keys returns for a registered key.
+type mcpKey struct {
+	EnvVar  string    `json:"env_var"`
+	Kind    string    `json:"kind"`
+	Fields  []string  `json:"fields,omitempty"`
+	AddedAt time.Time `json:"added_at"`
+}
+
+func mcpListKeys(_ json.RawMessage) (string, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+
+	keys := make([]mcpKey, 0, len(cfg.Keys))
+	for i := range cfg.Keys {
+		k := &cfg.Keys[i]
+		kind := "keychain"
+		switch {
+		case k.IsDerived():
+			kind = "derived"
+		case k.Kind != "":
+			kind = k.Kind
+		}
+		keys = append(keys, mcpKey{EnvVar: k.EnvVar, Kind: kind, Fields: k.Fields, AddedAt: k.AddedAt})
+	}
+
+	data, err := json.MarshalIndent(keys, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func mcpRunCommand(raw json.RawMessage) (string, error) {
+	var args struct {
+		Command        []string `json:"command"`
+		Keys           []string `json:"keys"`
+		Cwd            string   `json:"cwd"`
+		TimeoutSeconds int      `json:"timeout_seconds"`
+	}
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return "", fmt.Errorf("invalid arguments: %w", err)
+	}
+	if len(args.Command) == 0 {
+		return "", fmt.Errorf("command is required")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return "", err
+	}
+
+	policy := newKeyPolicy(cfg, args.Command[0])
+	names := make([]string, 0, len(args.Keys))
+	for _, arg := range args.Keys {
+		entry, err := resolveKey(cfg, arg)
+		if err != nil {
+			return "", err
+		}
+		for _, envVar := range entry.EnvVars() {
+			if d := policy.check(envVar); !d.allowed {
+				return "", fmt.Errorf("policy forbids passing %s to %s (%s)", envVar, policy.name, d.reason)
+			}
+		}
+		names = append(names, entry.EnvVar)
+	}
+
+	commandLine := strings.Join(args.Command, " ")
+	if !cfg.MCPAllowed(commandLine, names) {
+		prompt := fmt.Sprintf("sekret: an MCP client wants to run\n  %s\nwith %s. Allow? [y/N] ", quoteCommand(args.Command), strings.Join(names, ", "))
+		ok, err := readApproval(prompt)
+		if err != nil {
+			return "", fmt.Errorf("approval required but %v (pre-approve with 'sekret mcp allow')", err)
+		}
+		if !ok {
+			return "", fmt.Errorf("the user denied running %q", commandLine)
+		}
+	}
+
+	values, err := selectKeyValues(cfg, names)
+	if err != nil {
+		return "", err
+	}
+
+	env := os.Environ()
+	files := &secretFiles{}
+	defer files.cleanup()
+	for _, kv := range values {
+		value := kv.value
+		if kv.file {
+			if value, err = files.write(kv.envVar, kv.value); err != nil {
+				return "", err
+			}
+		}
+		env = setEnv(env, kv.envVar, value)
+	}
+
+	recordAudit("read", args.Command[0], auditKeys(values)...)
+
+	timeout := mcpDefaultTimeout
+	if args.TimeoutSeconds > 0 {
+		timeout = time.Duration(args.TimeoutSeconds) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var output bytes.Buffer
+	c := exec.CommandContext(ctx, args.Command[0], args.Command[1:]...)
+	c.Env = env
+	c.Dir = args.Cwd
+	c.Stdout = &output
+	c.Stderr = &output
+	c.WaitDelay = mcpWaitDelay
+
+	code := 0
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		switch {
+		case ctx.Err() != nil:
+			return "", fmt.Errorf("command timed out after %s", timeout)
+		case errors.Is(err, exec.ErrWaitDelay):
+			code = c.ProcessState.ExitCode() // exited, but left a process holding its output
+		case errors.As(err, &exitErr):
+			code = exitErr.ExitCode()
+		default:
+			return "", fmt.Errorf("failed to run %s: %w", args.Command[0], err)
+		}
+	}
+
+	text := redact.String(output.String(), secretValues(values))
+	if len(text) > mcpMaxOutput {
+		text = text[:mcpMaxOutput] + "\n[output truncated]"
+	}
+	return fmt.Sprintf("exit code: %d\n%s", code, text), nil
+}
+
+// quoteCommand renders a command line for the approval prompt, quoting
+// arguments with spaces or unprintable characters so that a crafted
+// argument cannot disguise what is being approved.
+func quoteCommand(command []string) string {
+	quoted := make([]string, len(command))
+	for i, arg := range command {
+		if arg == "" || strings.ContainsFunc(arg, func(r rune) bool { return r == '"' || unicode.IsSpace(r) || !unicode.IsPrint(r) }) {
+			arg = strconv.Quote(arg)
+		}
+		quoted[i] = arg
+	}
+	return strings.Join(quoted, " ")
+}
+
+// selectKeyValues reads the values of the named keys, expanding
+// multi-field entries into their fields.
+func selectKeyValues(cfg *config.Config, names []string) ([]keyValue, error) {
+	r := newValueResolver(cfg)
+	var values []keyValue
+	for _, name := range names {
+		entry, err := resolveKey(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if entry.IsMulti() {
+			fields, err := r.fields(entry)
+			if err != nil {
+				return nil, fmt.Errorf("could not read key %q: %w", entry.EnvVar, err)
+			}
+			for _, f := range entry.Fields {
+				if v, ok := fields[f]; ok {
+					values = append(values, keyValue{envVar: f, value: v})
+				}
+			}
+			continue
+		}
+
+		val, err := r.value(entry)
+		if err != nil {
+			return nil, fmt.Errorf("could not read key %q: %w", entry.EnvVar, err)
+		}
+		values = append(values, keyValue{envVar: entry.EnvVar, value: val, file: entry.IsFile(), plain: entry.IsPlain()})
+	}
+	return values, nil
+}
+
+func runMCPAllow(c *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(mcpAllowKeys))
+	for _, k := range mcpAllowKeys {
+		if strings.Contains(k, "*") {
+			keys = append(keys, k)
+			continue
+		}
+		entry, err := resolveKey(cfg, k)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, entry.EnvVar)
+	}
+
+	cfg.AddMCPRule(config.MCPRule{Command: args[0], Keys: keys})
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.ErrOrStderr(), "  Allowed %q with %s\n", args[0], strings.Join(keys, ", "))
+	return nil
+}
@@ -0,0 +1,111 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eazyhozy/sekret/cmd"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const awsFields = "AWS_ACCESS_KEY_ID,AWS_SECRET_ACCESS_KEY,AWS_SESSION_TOKEN"
+
+// seedAWS registers a multi-field "aws-dev" entry through the add command.
+func seedAWS(t *testing.T) {
+	t.Helper()
+	cmd.SetReadPassword(passwordSequence("AKIAEXAMPLE12345", "secret-access-key", "session-token"))
+	require.NoError(t, executeCmd(t, "add", "aws-dev", "--fields", awsFields))
+}
+
+func TestMulti_AddStoresSingleItem(t *testing.T) {
+	setup(t)
+	seedAWS(t)
+
+	cfg, _ := config.Load()
+	entry := cfg.FindKeyByEnvVar("aws-dev")
+	require.NotNil(t, entry)
+	assert.True(t, entry.IsMulti())
+	assert.Equal(t, []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"}, entry.Fields)
+
+	raw, err := testStore.Get("aws-dev")
+	require.NoError(t, err)
+	assert.Contains(t, raw, "secret-access-key")
+	_, err = testStore.Get("AWS_ACCESS_KEY_ID")
+	assert.Error(t, err, "fields should not be stored as separate items")
+}
+
+func TestMulti_EnvExportsFields(t *testing.T) {
+	setup(t)
+	seedAWS(t)
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "env"))
+	})
+
+	assert.Contains(t, output, `export AWS_ACCESS_KEY_ID="AKIAEXAMPLE12345"`)
+	assert.Contains(t, output, `export AWS_SECRET_ACCESS_KEY="secret-access-key"`)
+	assert.Contains(t, output, `export AWS_SESSION_TOKEN="session-token"`)
+	assert.NotContains(t, output, "aws-dev")
+}
+
+func TestMulti_SetKeepsUnchangedFields(t *testing.T) {
+	setup(t)
+	seedAWS(t)
+	cmd.SetReadPassword(passwordSequence("", "", "new-session-token"))
+
+	require.NoError(t, executeCmd(t, "set", "aws-dev"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "env"))
+	})
+	assert.Contains(t, output, `export AWS_ACCESS_KEY_ID="AKIAEXAMPLE12345"`)
+	assert.Contains(t, output, `export AWS_SESSION_TOKEN="new-session-token"`)
+}
+
+func TestMulti_ListShowsOneRow(t *testing.T) {
+	setup(t)
+	seedAWS(t)
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "list"))
+	})
+
+	assert.Equal(t, 1, strings.Count(output, "aws-dev"))
+	assert.NotContains(t, output, "secret-access-key")
+}
+
+func TestMulti_Remove(t *testing.T) {
+	setup(t)
+	seedAWS(t)
+	cmd.SetReadConfirm(func(_ string) (bool, error) { return true, nil })
+
+	require.NoError(t, executeCmd(t, "remove", "aws-dev"))
+
+	_, err := testStore.Get("aws-dev")
+	assert.Error(t, err)
+	cfg, _ := config.Load()
+	assert.Empty(t, cfg.Keys)
+}
+
+func TestMulti_FieldConflictsWithExistingKey(t *testing.T) {
+	setup(t)
+	seedKey(t, "AWS_SESSION_TOKEN", "existing")
+
+	err := executeCmd(t, "add", "aws-dev", "--fields", awsFields)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AWS_SESSION_TOKEN")
+}
+
+func TestMulti_FieldReference(t *testing.T) {
+	setupRun(t)
+	seedAWS(t)
+	t.Setenv("MY_SECRET", "sekret://AWS_SECRET_ACCESS_KEY")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c", `printf %s "$MY_SECRET"`))
+	})
+
+	assert.Equal(t, "secret-access-key", output)
+}
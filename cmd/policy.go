@@ -0,0 +1,230 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/derive"
+	"github.com/spf13/cobra"
+)
+
+var policyWarn bool
+
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Restrict which commands may receive which keys",
+	Long: `Manage per-key access policies enforced by 'sekret run'.
+
+A policy limits the keys matching a glob to a set of commands. Other
+commands run through 'sekret run' do not receive those keys, and a
+sekret:// reference to them is refused:
+
+  sekret policy add 'AWS_*' aws terraform
+  sekret policy add OPENAI_API_KEY python node
+
+Commands are matched by executable name, or by path if they contain a
+slash. Keys without a policy are passed to every command.
+
+Use 'sekret run --explain' to see why each key was or wasn't injected.`,
+}
+
+var policyAddCmd = &cobra.Command{
+	Use:   "add <KEY_GLOB> <COMMAND>...",
+	Short: "Allow keys matching a glob only for the given commands",
+	Args:  cobra.MinimumNArgs(2),
+	RunE:  runPolicyAdd,
+}
+
+var policyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List key policies",
+	Args:  cobra.NoArgs,
+	RunE:  runPolicyList,
+}
+
+var policyRemoveCmd = &cobra.Command{
+	Use:   "remove <KEY_GLOB>",
+	Short: "Remove a key policy",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runPolicyRemove,
+}
+
+func init() {
+	policyAddCmd.Flags().BoolVar(&policyWarn, "warn", false, "inject keys anyway and only warn about violations")
+	policyCmd.AddCommand(policyAddCmd, policyListCmd, policyRemoveCmd)
+	rootCmd.AddCommand(policyCmd)
+}
+
+// keyPolicy evaluates the configured policies for one command.
+type keyPolicy struct {
+	cfg  *config.Config
+	name string
+	path string
+}
+
+// newKeyPolicy returns the policy checker for running command.
+func newKeyPolicy(cfg *config.Config, command string) *keyPolicy {
+	path := command
+	if p, err := exec.LookPath(command); err == nil {
+		if abs, err := filepath.Abs(p); err == nil {
+			path = abs
+		}
+	}
+	return &keyPolicy{cfg: cfg, name: filepath.Base(command), path: path}
+}
+
+// policyDecision is the outcome of checking one key against the policies.
+type policyDecision struct {
+	allowed bool   // the key may be injected
+	warn    bool   // a warn-only policy was violated
+	reason  string // human-readable explanation
+}
+
+// check decides whether envVar may be passed to the command. A key is
+// allowed if no policy applies or any applicable policy lists the command.
+// A field of a multi-field entry is also governed by the entry's policies,
+// and a derived variable is withheld if any key its template references
+// is, since its value contains theirs.
+func (p *keyPolicy) check(envVar string) policyDecision {
+	return p.checkKey(envVar, map[string]bool{})
+}
+
+func (p *keyPolicy) checkKey(envVar string, seen map[string]bool) policyDecision {
+	if entry := previousOf(p.cfg, envVar); entry != nil {
+		envVar = entry.EnvVar // X_PREVIOUS is governed by X's policies
+	}
+	d := p.checkOwn(envVar)
+	if parent := p.cfg.FindKeyByField(envVar); parent != nil && d.allowed {
+		if pd := p.checkOwn(parent.EnvVar); !pd.allowed || pd.warn && !d.warn {
+			d = pd
+		}
+	}
+	entry := p.cfg.FindKeyByEnvVar(envVar)
+	if !d.allowed || entry == nil || !entry.IsDerived() || seen[envVar] {
+		return d
+	}
+	seen[envVar] = true
+
+	refs, _ := derive.References(entry.Template)
+	for _, ref := range refs {
+		ref = canonicalName(p.cfg, ref) // templates may use shorthand or legacy names
+		rd := p.checkKey(ref, seen)
+		switch {
+		case !rd.allowed:
+			return policyDecision{allowed: false, reason: fmt.Sprintf("references %s (%s)", ref, rd.reason)}
+		case rd.warn && !d.warn:
+			d = policyDecision{allowed: true, warn: true, reason: fmt.Sprintf("references %s (%s)", ref, rd.reason)}
+		}
+	}
+	return d
+}
+
+// checkOwn applies the policies matching envVar itself.
+func (p *keyPolicy) checkOwn(envVar string) policyDecision {
+	policies := p.cfg.PoliciesFor(envVar)
+	if len(policies) == 0 {
+		return policyDecision{allowed: true, reason: "no policy"}
+	}
+
+	var allowed []string
+	warnOnly := true
+	for _, pol := range policies {
+		if pol.AllowsCommand(p.name, p.path) {
+			return policyDecision{allowed: true, reason: fmt.Sprintf("policy %s allows %s", pol.Keys, p.name)}
+		}
+		allowed = append(allowed, pol.Commands...)
+		warnOnly = warnOnly && pol.IsWarn()
+	}
+
+	reason := fmt.Sprintf("only for %s", strings.Join(allowed, ", "))
+	if warnOnly {
+		return policyDecision{allowed: true, warn: true, reason: reason + " (warn only)"}
+	}
+	return policyDecision{allowed: false, reason: reason}
+}
+
+// warnViolation reports a key passed despite a warn-only policy.
+func (p *keyPolicy) warnViolation(envVar string, d policyDecision) {
+	_, _ = fmt.Fprintf(rootCmd.ErrOrStderr(), "sekret: warning: policy violation: %s passed to %s (%s)\n", envVar, p.name, d.reason)
+}
+
+// explain prints the decision for every registered key to stderr.
+func (p *keyPolicy) explain() {
+	w := tabwriter.NewWriter(rootCmd.ErrOrStderr(), 0, 0, 2, ' ', 0)
+	for i := range p.cfg.Keys {
+		for _, envVar := range p.cfg.Keys[i].EnvVars() {
+			d := p.check(envVar)
+			verdict := "injected"
+			if !d.allowed {
+				verdict = "withheld"
+			}
+			_, _ = fmt.Fprintf(w, "  %s\t%s\t%s\n", envVar, verdict, d.reason)
+		}
+	}
+	_ = w.Flush()
+}
+
+func runPolicyAdd(c *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	pol := config.Policy{Keys: args[0], Commands: args[1:]}
+	if policyWarn {
+		pol.Action = config.PolicyWarn
+	}
+	cfg.SetPolicy(pol)
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.ErrOrStderr(), "  %s -> %s\n", pol.Keys, strings.Join(pol.Commands, ", "))
+	return nil
+}
+
+func runPolicyList(c *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Policies) == 0 {
+		_, _ = fmt.Fprintln(c.ErrOrStderr(), "No policies. Add one with: sekret policy add <KEY_GLOB> <COMMAND>...")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "Keys\tCommands\tAction")
+	_, _ = fmt.Fprintln(w, "----\t--------\t------")
+	for _, pol := range cfg.Policies {
+		action := pol.Action
+		if action == "" {
+			action = config.PolicyEnforce
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", pol.Keys, strings.Join(pol.Commands, ", "), action)
+	}
+	return w.Flush()
+}
+
+func runPolicyRemove(c *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if err := cfg.RemovePolicy(args[0]); err != nil {
+		return err
+	}
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.ErrOrStderr(), "  Removed policy for %s\n", args[0])
+	return nil
+}
@@ -0,0 +1,158 @@
+package cmd_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eazyhozy/sekret/cmd"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_AddListRemove(t *testing.T) {
+	setup(t)
+
+	require.NoError(t, executeCmd(t, "policy", "add", "AWS_*", "aws", "terraform"))
+	require.NoError(t, executeCmd(t, "policy", "add", "OPENAI_API_KEY", "python", "--warn"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "policy", "list"))
+	})
+	assert.Contains(t, output, "AWS_*")
+	assert.Contains(t, output, "aws, terraform")
+	assert.Contains(t, output, "warn")
+
+	require.NoError(t, executeCmd(t, "policy", "remove", "AWS_*"))
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	require.Len(t, cfg.Policies, 1)
+	assert.Equal(t, config.PolicyWarn, cfg.Policies[0].Action)
+
+	assert.Error(t, executeCmd(t, "policy", "remove", "AWS_*"))
+}
+
+func TestRun_PolicyWithholdsKeys(t *testing.T) {
+	setupRun(t)
+	seedAWS(t)
+	seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+	require.NoError(t, executeCmd(t, "policy", "add", "AWS_*", "aws", "terraform"))
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "inherited-from-shell")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c",
+			`printf '%s|%s|%s' "${AWS_ACCESS_KEY_ID:-none}" "${AWS_SECRET_ACCESS_KEY:-none}" "$GITHUB_TOKEN"`))
+	})
+
+	assert.Equal(t, "none|none|ghp_abc123", output)
+}
+
+func TestRun_PolicyAllowsListedCommand(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+	require.NoError(t, executeCmd(t, "policy", "add", "OPENAI_*", "sh"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c", `printf %s "$OPENAI_API_KEY"`))
+	})
+
+	assert.Equal(t, "sk-test123", output)
+}
+
+func TestRun_PolicyMatchesPath(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+
+	dir := t.TempDir()
+	script := filepath.Join(dir, "tool")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\nprintf %s \"$OPENAI_API_KEY\"\n"), 0o755))
+	require.NoError(t, executeCmd(t, "policy", "add", "OPENAI_API_KEY", dir+"/*"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", script))
+	})
+
+	assert.Equal(t, "sk-test123", output)
+}
+
+func TestRun_PolicyRefusesReference(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+	require.NoError(t, executeCmd(t, "policy", "add", "OPENAI_API_KEY", "python"))
+	t.Setenv("MY_KEY", "sekret://openai")
+
+	err := executeCmd(t, "run", "--", "true")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "policy forbids passing OPENAI_API_KEY to true")
+}
+
+func TestRun_PolicyWarnOnly(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+	require.NoError(t, executeCmd(t, "policy", "add", "OPENAI_API_KEY", "python", "--warn"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c", `printf %s "$OPENAI_API_KEY"`))
+	})
+
+	assert.Equal(t, "sk-test123", output)
+}
+
+func TestRun_Explain(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+	seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+	require.NoError(t, executeCmd(t, "policy", "add", "OPENAI_API_KEY", "python", "node"))
+
+	var stderrBuf bytes.Buffer
+	cmd.RootCmd().SetErr(&stderrBuf)
+	t.Cleanup(func() { cmd.RootCmd().SetErr(nil) })
+
+	require.NoError(t, executeCmd(t, "run", "--explain", "--", "true"))
+
+	stderr := stderrBuf.String()
+	assert.Regexp(t, `OPENAI_API_KEY\s+withheld\s+only for python, node`, stderr)
+	assert.Regexp(t, `GITHUB_TOKEN\s+injected\s+no policy`, stderr)
+}
+
+func TestRun_PolicyWithholdsDerivedReferences(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "DB_PASSWORD", "hunter2")
+	require.NoError(t, executeCmd(t, "add", "DATABASE_URL", "--template", "postgres://u:${DB_PASSWORD}@db"))
+	require.NoError(t, executeCmd(t, "policy", "add", "DB_PASSWORD", "psql"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c", `printf %s "${DATABASE_URL:-none}"`))
+	})
+
+	assert.Equal(t, "none", output)
+}
+
+func TestRun_PolicyWithholdsDerivedShorthandReferences(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+	require.NoError(t, executeCmd(t, "add", "OPENAI_AUTH", "--template", "Bearer ${openai}"))
+	require.NoError(t, executeCmd(t, "policy", "add", "OPENAI_API_KEY", "python"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c", `printf %s "${OPENAI_AUTH:-none}"`))
+	})
+
+	assert.Equal(t, "none", output)
+}
+
+func TestRun_PolicyWithholdsDerivedFieldReferences(t *testing.T) {
+	setupRun(t)
+	seedAWS(t)
+	require.NoError(t, executeCmd(t, "add", "AWS_AUTH", "--template", "${AWS_ACCESS_KEY_ID}:${AWS_SECRET_ACCESS_KEY}"))
+	require.NoError(t, executeCmd(t, "policy", "add", "aws-dev", "aws"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c",
+			`printf '%s|%s' "${AWS_AUTH:-none}" "${AWS_ACCESS_KEY_ID:-none}"`))
+	})
+
+	assert.Equal(t, "none|none", output)
+}
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/proxy"
+	"github.com/eazyhozy/sekret/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var (
+	proxyListen string
+
+	upstreamBaseURLEnv string
+	upstreamBasePath   string
+	upstreamHeader     string
+	upstreamScheme     string
+)
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Serve a local proxy that injects keys into API requests",
+	Long: `Start a local HTTP proxy that forwards requests to provider APIs and adds
+the real key on the way out. Clients are given a random placeholder key and
+a base URL pointing at the proxy, so the real key never enters their
+environment, memory or logs.
+
+The proxy prints the variables to export and runs until interrupted:
+
+  eval "$(sekret proxy)"
+
+Keys for OpenAI, Anthropic, Gemini and Groq are proxied out of the box.
+Other keys can be proxied after configuring an upstream:
+
+  sekret proxy upstream MISTRAL_API_KEY https://api.mistral.ai --base-url-env MISTRAL_BASE_URL --base-path /v1
+
+Use 'sekret run --via-proxy' to start the proxy just for one command.`,
+	Args: cobra.NoArgs,
+	RunE: runProxy,
+}
+
+var proxyUpstreamCmd = &cobra.Command{
+	Use:   "upstream <ENV_VAR> <URL>",
+	Short: "Configure the upstream API a key is proxied to",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runProxyUpstream,
+}
+
+func init() {
+	proxyCmd.Flags().StringVar(&proxyListen, "listen", "127.0.0.1:0", "address to listen on")
+	proxyUpstreamCmd.Flags().StringVar(&upstreamBaseURLEnv, "base-url-env", "", "env var clients read the base URL from")
+	proxyUpstreamCmd.Flags().StringVar(&upstreamBasePath, "base-path", "", "path appended to the base URL, e.g. /v1")
+	proxyUpstreamCmd.Flags().StringVar(&upstreamHeader, "header", "", "header carrying the key (default Authorization)")
+	proxyUpstreamCmd.Flags().StringVar(&upstreamScheme, "scheme", "", "header value prefix, e.g. Bearer")
+	proxyCmd.AddCommand(proxyUpstreamCmd)
+	rootCmd.AddCommand(proxyCmd)
+}
+
+// proxyUpstream merges the key's configured upstream over the registry
+// default. It returns nil if the key cannot be proxied.
+func proxyUpstream(entry *config.KeyEntry) *registry.Upstream {
+	up := registry.Upstream{}
+	if reg := registry.LookupByEnvVar(entry.EnvVar); reg != nil && reg.Upstream != nil {
+		up = *reg.Upstream
+	}
+	if o := entry.Upstream; o != nil {
+		if o.URL != "" {
+			up.URL = o.URL
+		}
+		if o.BaseURLEnv != "" {
+			up.BaseURLEnv = o.BaseURLEnv
+		}
+		if o.BasePath != "" {
+			up.BasePath = o.BasePath
+		}
+		if o.Header != "" {
+			up.Header = o.Header
+		}
+		if o.Scheme != "" {
+			up.Scheme = o.Scheme
+		}
+	}
+	if up.URL == "" || up.BaseURLEnv == "" {
+		return nil
+	}
+	if up.Header == "" {
+		up.Header = "Authorization"
+	}
+	return &up
+}
+
+// proxySession is a running proxy and the keys it serves.
+type proxySession struct {
+	listener    net.Listener
+	server      *http.Server
+	placeholder string
+	keys        []proxiedKey
+}
+
+// proxiedKey records the env vars a client needs to reach one route.
+type proxiedKey struct {
+	envVar     string
+	baseURLEnv string
+	basePath   string
+	secret     string
+}
+
+// startProxy serves every proxiable key in cfg on addr in the background.
+// If policy is not nil, keys it withholds from the command are not served.
+func startProxy(cfg *config.Config, addr string, policy *keyPolicy) (*proxySession, error) {
+	resolver := newValueResolver(cfg)
+	var routes []proxy.Route
+	var keys []proxiedKey
+	for i := range cfg.Keys {
+		entry := &cfg.Keys[i]
+		if entry.IsMulti() || entry.IsFile() {
+			continue
+		}
+		up := proxyUpstream(entry)
+		if up == nil {
+			continue
+		}
+		if policy != nil && !policy.check(entry.EnvVar).allowed {
+			continue
+		}
+		target, err := url.Parse(up.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid upstream for %s: %w", entry.EnvVar, err)
+		}
+		secret, err := resolver.value(entry)
+		if err != nil {
+			_, _ = fmt.Fprintf(rootCmd.ErrOrStderr(), "sekret: warning: could not read %s from keychain: %v\n", entry.EnvVar, err)
+			continue
+		}
+		routes = append(routes, proxy.Route{
+			Name: entry.EnvVar, Upstream: target, Header: up.Header, Scheme: up.Scheme, Secret: secret,
+		})
+		keys = append(keys, proxiedKey{envVar: entry.EnvVar, baseURLEnv: up.BaseURLEnv, basePath: up.BasePath, secret: secret})
+	}
+	if len(routes) == 0 {
+		return nil, fmt.Errorf("no registered keys can be proxied (see 'sekret proxy upstream')")
+	}
+
+	placeholder, err := proxy.NewPlaceholder()
+	if err != nil {
+		return nil, err
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	s := &proxySession{
+		listener:    ln,
+		server:      &http.Server{Handler: proxy.New(routes, placeholder)},
+		placeholder: placeholder,
+		keys:        keys,
+	}
+	go func() { _ = s.server.Serve(ln) }()
+	return s, nil
+}
+
+// env returns the variables a client of the proxy should see: the
+// placeholder in place of each key and the proxy as each base URL.
+func (s *proxySession) env() []keyValue {
+	base := "http://" + s.listener.Addr().String()
+	var vars []keyValue
+	for _, k := range s.keys {
+		vars = append(vars,
+			keyValue{envVar: k.envVar, value: s.placeholder},
+			keyValue{envVar: k.baseURLEnv, value: base + "/" + k.envVar + k.basePath},
+		)
+	}
+	return vars
+}
+
+// apply points a client environment at the proxy. Variables that would
+// still reveal a proxied key are removed: the key's previous value, and any
+// value containing the key, such as a derived variable or a resolved
+// sekret:// reference.
+func (s *proxySession) apply(env []string) []string {
+	for _, k := range s.keys {
+		env = slices.DeleteFunc(env, func(kv string) bool {
+			name, value, _ := strings.Cut(kv, "=")
+			reveals := name == k.envVar+previousSuffix || strings.Contains(value, k.secret)
+			if name == k.envVar || !reveals {
+				return false
+			}
+			_, _ = fmt.Fprintf(rootCmd.ErrOrStderr(), "sekret: withholding %s: it would reveal the proxied key %s\n", name, k.envVar)
+			return true
+		})
+	}
+	for _, kv := range s.env() {
+		env = setEnv(env, kv.envVar, kv.value)
+	}
+	return env
+}
+
+// keyNames returns the env vars of the keys the proxy serves.
+func (s *proxySession) keyNames() []string {
+	names := make([]string, len(s.keys))
+	for i, k := range s.keys {
+		names[i] = k.envVar
+	}
+	return names
+}
+
+// Close stops the proxy.
+func (s *proxySession) Close() {
+	_ = s.server.Close()
+}
+
+func runProxy(_ *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	session, err := startProxy(cfg, proxyListen, nil)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	for _, kv := range session.env() {
+		fmt.Printf("export %s=%s\n", kv.envVar, shellQuote(kv.value))
+	}
+	_, _ = fmt.Fprintf(rootCmd.ErrOrStderr(), "  sekret proxy listening on %s (Ctrl-C to stop)\n", session.listener.Addr())
+	recordAudit("read", "", session.keyNames()...)
+
+	<-signals
+	return nil
+}
+
+func runProxyUpstream(c *cobra.Command, args []string) error {
+	arg, rawURL := args[0], args[1]
+
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid upstream URL %q: must be an http(s) URL", rawURL)
+	}
+	if upstreamBasePath != "" && !strings.HasPrefix(upstreamBasePath, "/") {
+		return fmt.Errorf("--base-path must start with /")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	entry, err := resolveKey(cfg, arg)
+	if err != nil {
+		return err
+	}
+	if entry.IsMulti() || entry.IsFile() {
+		return fmt.Errorf("%s cannot be proxied", entry.EnvVar)
+	}
+
+	entry.Upstream = &config.Upstream{
+		URL:        strings.TrimSuffix(rawURL, "/"),
+		BaseURLEnv: upstreamBaseURLEnv,
+		BasePath:   upstreamBasePath,
+		Header:     upstreamHeader,
+		Scheme:     upstreamScheme,
+	}
+	if proxyUpstream(entry) == nil {
+		return fmt.Errorf("--base-url-env is required for keys without a built-in upstream")
+	}
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.ErrOrStderr(), "  %s -> %s\n", entry.EnvVar, entry.Upstream.URL)
+	return nil
+}
@@ -0,0 +1,164 @@
+package cmd_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/eazyhozy/sekret/cmd"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoUpstream replies with the request path and auth header it received.
+func echoUpstream(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path + " " + r.Header.Get("Authorization")))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestProxyUpstream_OverridesRegistryDefault(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-real")
+
+	require.NoError(t, executeCmd(t, "proxy", "upstream", "openai", "https://llm.internal.example/"))
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	entry := cfg.FindKeyByEnvVar("OPENAI_API_KEY")
+	require.NotNil(t, entry.Upstream)
+	assert.Equal(t, "https://llm.internal.example", entry.Upstream.URL)
+	assert.Empty(t, entry.Upstream.BaseURLEnv, "registry default should still apply")
+}
+
+func TestProxyUpstream_CustomKeyNeedsBaseURLEnv(t *testing.T) {
+	setup(t)
+	seedKey(t, "MISTRAL_API_KEY", "real")
+
+	err := executeCmd(t, "proxy", "upstream", "MISTRAL_API_KEY", "https://api.mistral.ai")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--base-url-env")
+
+	require.NoError(t, executeCmd(t, "proxy", "upstream", "MISTRAL_API_KEY", "https://api.mistral.ai",
+		"--base-url-env", "MISTRAL_BASE_URL", "--base-path", "/v1", "--scheme", "Bearer"))
+}
+
+func TestProxyUpstream_InvalidURL(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-real")
+
+	err := executeCmd(t, "proxy", "upstream", "OPENAI_API_KEY", "api.openai.com")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid upstream URL")
+}
+
+func TestRun_ViaProxyHidesKey(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-real")
+	seedKey(t, "OTHER_TOKEN", "other")
+	up := echoUpstream(t)
+	require.NoError(t, executeCmd(t, "proxy", "upstream", "OPENAI_API_KEY", up.URL))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--via-proxy", "--", "sh", "-c",
+			`printf '%s\n%s\n%s\n' "$OPENAI_API_KEY" "$OPENAI_BASE_URL" "$OTHER_TOKEN"`))
+	})
+
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], "sekret-proxy-"), "key should be a placeholder")
+	assert.Regexp(t, `^http://127\.0\.0\.1:\d+/OPENAI_API_KEY/v1$`, lines[1])
+	assert.Equal(t, "other", lines[2], "keys without an upstream are injected as usual")
+	assert.NotContains(t, output, "sk-real")
+}
+
+func TestRun_ViaProxyForwardsRequests(t *testing.T) {
+	if _, err := exec.LookPath("curl"); err != nil {
+		t.Skip("curl not available")
+	}
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-real")
+	up := echoUpstream(t)
+	require.NoError(t, executeCmd(t, "proxy", "upstream", "OPENAI_API_KEY", up.URL))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--via-proxy", "--", "sh", "-c",
+			`curl -sf -H "Authorization: Bearer $OPENAI_API_KEY" "$OPENAI_BASE_URL/models"`))
+	})
+
+	assert.Equal(t, "/v1/models Bearer sk-real", output)
+}
+
+func TestRun_ViaProxyNothingToProxy(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+
+	err := executeCmd(t, "run", "--via-proxy", "--", "true")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no registered keys can be proxied")
+}
+
+func TestRun_ViaProxyRespectsPolicy(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-real")
+	seedKey(t, "ANTHROPIC_API_KEY", "sk-ant-real")
+	up := echoUpstream(t)
+	require.NoError(t, executeCmd(t, "proxy", "upstream", "OPENAI_API_KEY", up.URL))
+	require.NoError(t, executeCmd(t, "proxy", "upstream", "ANTHROPIC_API_KEY", up.URL))
+	require.NoError(t, executeCmd(t, "policy", "add", "ANTHROPIC_API_KEY", "python"))
+	t.Setenv("ANTHROPIC_BASE_URL", "")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--via-proxy", "--", "sh", "-c",
+			`printf '%s|%s|%s' "${ANTHROPIC_API_KEY:-none}" "${ANTHROPIC_BASE_URL:-none}" "$OPENAI_BASE_URL"`))
+	})
+
+	parts := strings.Split(output, "|")
+	require.Len(t, parts, 3)
+	assert.Equal(t, "none", parts[0])
+	assert.Equal(t, "none", parts[1], "withheld keys get no proxy route")
+	assert.Contains(t, parts[2], "/OPENAI_API_KEY/v1")
+}
+
+func TestRun_ViaProxyWithholdsDerivedVariables(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-real")
+	require.NoError(t, executeCmd(t, "add", "OPENAI_AUTH", "--template", "Bearer ${OPENAI_API_KEY}"))
+	up := echoUpstream(t)
+	require.NoError(t, executeCmd(t, "proxy", "upstream", "OPENAI_API_KEY", up.URL))
+	t.Setenv("OPENAI_REF", "sekret://openai")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--via-proxy", "--", "sh", "-c",
+			`printf '%s|%s|%s' "${OPENAI_AUTH:-none}" "${OPENAI_REF:-none}" "$OPENAI_API_KEY"`))
+	})
+
+	parts := strings.Split(output, "|")
+	require.Len(t, parts, 3)
+	assert.Equal(t, "none", parts[0])
+	assert.Equal(t, "none", parts[1])
+	assert.True(t, strings.HasPrefix(parts[2], "sekret-proxy-"))
+	assert.NotContains(t, output, "sk-real")
+}
+
+func TestRun_ViaProxyWithholdsPrevious(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-old-value-123456")
+	cmd.SetReadPassword(func(_ string) (string, error) { return "sk-new-value-123456", nil })
+	require.NoError(t, executeCmd(t, "set", "OPENAI_API_KEY", "--overlap", "1h"))
+	up := echoUpstream(t)
+	require.NoError(t, executeCmd(t, "proxy", "upstream", "OPENAI_API_KEY", up.URL))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--via-proxy", "--", "sh", "-c",
+			`printf %s "${OPENAI_API_KEY_PREVIOUS:-none}"`))
+	})
+
+	assert.Equal(t, "none", output)
+}
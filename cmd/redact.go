@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+var redactCmd = &cobra.Command{
+	Use:   "redact",
+	Short: "Mask registered key values in text read from stdin",
+	Long: `Copy stdin to stdout, masking every registered key value. Use it to scrub
+logs before sharing them:
+
+  ./deploy.sh 2>&1 | sekret redact | tee deploy.log
+
+Plain variables are not secrets and are left as-is.`,
+	Args: cobra.NoArgs,
+	RunE: runRedactFilter,
+}
+
+func init() {
+	rootCmd.AddCommand(redactCmd)
+}
+
+// secretValues returns the values worth masking: everything except plain
+// variables.
+func secretValues(values []keyValue) []string {
+	secrets := make([]string, 0, len(values))
+	for _, kv := range values {
+		if !kv.plain {
+			secrets = append(secrets, kv.value)
+		}
+	}
+	return secrets
+}
+
+func runRedactFilter(c *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	values := loadKeyValues(cfg)
+	recordAudit("read", "", auditKeys(values)...)
+	w := redact.NewWriter(c.OutOrStdout(), secretValues(values))
+	if _, err := io.Copy(w, c.InOrStdin()); err != nil {
+		return fmt.Errorf("failed to redact input: %w", err)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,65 @@
+package cmd_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/eazyhozy/sekret/cmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_Redact(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-proj-abcdefgh1234")
+	require.NoError(t, executeCmd(t, "add", "LOG_LEVEL", "--plain", "debug"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--redact", "--", "sh", "-c",
+			`printf 'key=%s level=%s\n' "$OPENAI_API_KEY" "$LOG_LEVEL"`))
+	})
+
+	assert.Equal(t, "key=sk-proj-...1234 level=debug\n", output)
+}
+
+func TestRun_WithoutRedactPassesThrough(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-proj-abcdefgh1234")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c", `printf %s "$OPENAI_API_KEY"`))
+	})
+
+	assert.Equal(t, "sk-proj-abcdefgh1234", output)
+}
+
+func TestRedact_Filter(t *testing.T) {
+	setup(t)
+	seedKey(t, "GITHUB_TOKEN", "ghp_abcdefghijklmnop")
+	seedAWS(t)
+
+	cmd.RootCmd().SetIn(strings.NewReader("push with ghp_abcdefghijklmnop\naws AKIAEXAMPLE12345 ok\n"))
+	t.Cleanup(func() { cmd.RootCmd().SetIn(nil) })
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "redact"))
+	})
+
+	assert.NotContains(t, output, "ghp_abcdefghijklmnop")
+	assert.NotContains(t, output, "AKIAEXAMPLE12345")
+	assert.Contains(t, output, "push with ghp_...mnop\n")
+	assert.Contains(t, output, " ok\n")
+}
+
+func TestRun_RedactMasksWithheldKeys(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-proj-abcdefgh1234")
+	require.NoError(t, executeCmd(t, "policy", "add", "OPENAI_API_KEY", "python"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--redact", "--", "sh", "-c",
+			`printf 'env=%s echo=%s\n' "$OPENAI_API_KEY" sk-proj-abcdefgh1234`))
+	})
+
+	assert.Equal(t, "env= echo=sk-proj-...1234\n", output)
+}
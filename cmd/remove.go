@@ -2,8 +2,11 @@ package cmd
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/derive"
 	"github.com/spf13/cobra"
 )
 
@@ -32,7 +35,12 @@ func runRemove(_ *cobra.Command, args []string) error {
 	}
 
 	// Confirmation prompt
-	confirmed, err := readConfirm(fmt.Sprintf("  Remove '%s'? [y/N]: ", entry.EnvVar))
+	prompt := fmt.Sprintf("  Remove '%s'? [y/N]: ", entry.EnvVar)
+	if deps := dependents(cfg, entry); len(deps) > 0 {
+		prompt = fmt.Sprintf("  %s %s derived from '%s' and will stop resolving.\n", strings.Join(deps, ", "),
+			pluralize(len(deps), "is", "are"), entry.EnvVar) + prompt
+	}
+	confirmed, err := readConfirm(prompt)
 	if err != nil {
 		return err
 	}
@@ -41,19 +49,37 @@ func runRemove(_ *cobra.Command, args []string) error {
 		return nil
 	}
 
-	// Delete from keychain
-	if err := store.Delete(entry.KeychainKey()); err != nil {
-		return err
-	}
-
-	// Delete from config (by env var)
-	if err := cfg.RemoveKey(entry.EnvVar); err != nil {
-		return err
-	}
-	if err := config.Save(cfg); err != nil {
+	// Move to trash (derived and plain entries have no keychain item)
+	envVar := entry.EnvVar
+	err = transaction("remove", func() error {
+		if err := trashKey(cfg, entry); err != nil {
+			return err
+		}
+		return config.Save(cfg)
+	})
+	if err != nil {
 		return err
 	}
 
-	_, _ = fmt.Fprintln(rootCmd.ErrOrStderr(), "  Removed")
+	recordAudit("remove", "", envVar)
+	_, _ = fmt.Fprintf(rootCmd.ErrOrStderr(), "  Removed (restore with 'sekret restore %s')\n", envVar)
 	return nil
 }
+
+// dependents returns the derived entries whose templates reference entry.
+func dependents(cfg *config.Config, entry *config.KeyEntry) []string {
+	var deps []string
+	for i := range cfg.Keys {
+		k := &cfg.Keys[i]
+		if !k.IsDerived() || k.EnvVar == entry.EnvVar {
+			continue
+		}
+		refs, _ := derive.References(k.Template)
+		if slices.ContainsFunc(refs, func(ref string) bool {
+			return slices.Contains(entry.EnvVars(), canonicalName(cfg, ref))
+		}) {
+			deps = append(deps, k.EnvVar)
+		}
+	}
+	return deps
+}
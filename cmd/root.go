@@ -3,7 +3,9 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 
 	"github.com/eazyhozy/sekret/internal/config"
 	"github.com/eazyhozy/sekret/internal/keychain"
@@ -76,6 +78,53 @@ func SetReadChoice(fn func(string) (string, error)) {
 	readChoice = fn
 }
 
+// readApproval asks the user at the terminal to approve an action. It reads
+// from /dev/tty because stdin may be in use, e.g. by an MCP client.
+// Override with SetReadApproval() for testing.
+var readApproval = func(prompt string) (bool, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false, fmt.Errorf("no terminal available for approval: %w", err)
+	}
+	defer func() { _ = tty.Close() }()
+
+	fmt.Fprint(tty, prompt)
+	var answer string
+	if _, err := fmt.Fscanln(tty, &answer); err != nil {
+		return false, nil
+	}
+	return answer == "y" || answer == "yes", nil
+}
+
+// SetReadApproval overrides the approval reader (for testing).
+func SetReadApproval(fn func(string) (bool, error)) {
+	readApproval = fn
+}
+
+// readTTYPassword reads a secret from /dev/tty, like readApproval, so that
+// it cannot be answered through stdin.
+// Override with SetReadTTYPassword() for testing.
+var readTTYPassword = func(prompt string) (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", fmt.Errorf("no terminal available: %w", err)
+	}
+	defer func() { _ = tty.Close() }()
+
+	fmt.Fprint(tty, prompt)
+	password, err := term.ReadPassword(int(tty.Fd()))
+	fmt.Fprintln(tty)
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return string(password), nil
+}
+
+// SetReadTTYPassword overrides the terminal password reader (for testing).
+func SetReadTTYPassword(fn func(string) (string, error)) {
+	readTTYPassword = fn
+}
+
 var rootCmd = &cobra.Command{
 	Use:     "sekret",
 	Version: version,
@@ -85,6 +134,40 @@ as environment variables. No more plaintext secrets in .zshrc.
 
 Add 'eval "$(sekret env)"' to your .zshrc to automatically load
 all registered keys when opening a new terminal.`,
+	PersistentPreRunE: func(c *cobra.Command, _ []string) error {
+		activeCommand = strings.TrimPrefix(c.CommandPath(), c.Root().Name()+" ")
+
+		err := withConfigLock(func() error {
+			recoverJournal(c.ErrOrStderr())
+			return migrateConfig(c)
+		})
+		if err != nil {
+			return err
+		}
+		purgeExpiredTrash(c)
+		return nil
+	},
+}
+
+// activeCommand is the running subcommand (e.g. "run"), recorded in the
+// audit log.
+var activeCommand string
+
+// withConfigLock runs fn holding the config lock. Commands take it only
+// around writes, so that a prompt or a long-running command never blocks
+// other sekret processes.
+func withConfigLock(fn func() error) error {
+	unlock, err := config.Lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// saveConfig saves cfg holding the config lock.
+func saveConfig(cfg *config.Config) error {
+	return withConfigLock(func() error { return config.Save(cfg) })
 }
 
 // RootCmd returns the root command for testing.
@@ -92,8 +175,19 @@ func RootCmd() *cobra.Command {
 	return rootCmd
 }
 
+// helperAliases maps binary names to the subcommand they run, so sekret can
+// be linked under the names tools look for (e.g. git-credential-sekret).
+var helperAliases = map[string]string{
+	"git-credential-sekret":    "git-credential",
+	"docker-credential-sekret": "docker-credential",
+}
+
 // Execute runs the root command.
 func Execute() error {
+	name := strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe")
+	if sub, ok := helperAliases[name]; ok {
+		rootCmd.SetArgs(append([]string{sub}, os.Args[1:]...))
+	}
 	return rootCmd.Execute()
 }
 
@@ -131,10 +225,28 @@ func resolveKey(cfg *config.Config, arg string) (*config.KeyEntry, error) {
 		}
 	}
 
-	// 3. Legacy name match (backward compat for v1 config entries)
+	// 3. Legacy name match (deprecated: v1 config entries, until re-keyed
+	// by the config upgrade or 'sekret migrate-legacy')
 	if entry := cfg.FindKey(arg); entry != nil {
 		return entry, nil
 	}
 
 	return nil, fmt.Errorf("key %q is not registered", arg)
 }
+
+// canonicalName returns the env var a CLI argument refers to, expanding
+// registry shorthands. Unknown names and multi-field fields are returned
+// as given.
+func canonicalName(cfg *config.Config, arg string) string {
+	if entry, err := resolveKey(cfg, arg); err == nil && !entry.IsMulti() {
+		return entry.EnvVar
+	}
+	return arg
+}
+
+// readKeyValue resolves a CLI argument to a registered key (or a field of a
+// multi-field entry) and reads its value from the keychain, or evaluates it
+// for derived entries.
+func readKeyValue(cfg *config.Config, arg string) (string, error) {
+	return newValueResolver(cfg).lookup(arg)
+}
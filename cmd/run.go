@@ -0,0 +1,371 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/envfile"
+	"github.com/eazyhozy/sekret/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+// refScheme is the prefix of a secret reference, e.g. sekret://OPENAI_API_KEY.
+const refScheme = "sekret://"
+
+var (
+	runEnvFile  string
+	runViaProxy bool
+	runRedact   bool
+	runExplain  bool
+)
+
+var runCmd = &cobra.Command{
+	Use:   "run [flags] -- <command> [args...]",
+	Short: "Run a command with registered keys injected as env vars",
+	Long: `Execute a command with all registered keys injected as environment variables.
+The keys exist only in the child process and do not persist in the current shell.
+
+Values of the form sekret://<ENV_VAR> are treated as references and replaced
+with the registered key's value, both in the current environment and in the
+file given by --env-file. This lets you commit .env files safely:
+
+  # .env
+  OPENAI_API_KEY=sekret://OPENAI_API_KEY
+
+  sekret run --env-file .env -- python main.py
+
+With --via-proxy, keys that 'sekret proxy' can serve are replaced with a
+placeholder and their base URL env vars point at a proxy started for the
+duration of the command, so the command never sees the real keys. Variables
+that would reveal a proxied key, such as a derived variable built from it
+or its _PREVIOUS value, are withheld.
+
+With --redact, registered key values printed by the command are masked in
+its stdout and stderr. The command's output is then a pipe, not a terminal.
+
+Keys restricted by 'sekret policy' to other commands are withheld; use
+--explain to see why each key was or wasn't injected.`,
+	RunE: runRun,
+}
+
+func init() {
+	runCmd.Flags().StringVar(&runEnvFile, "env-file", "", "load variables from a dotenv file (sekret:// references are resolved)")
+	runCmd.Flags().BoolVar(&runViaProxy, "via-proxy", false, "give the command placeholder keys and route API calls through a local proxy")
+	runCmd.Flags().BoolVar(&runRedact, "redact", false, "mask registered key values in the command's output")
+	runCmd.Flags().BoolVar(&runExplain, "explain", false, "show which keys are injected and why, according to key policies")
+	rootCmd.AddCommand(runCmd)
+}
+
+func runRun(_ *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("no command specified")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Keys) == 0 {
+		_, _ = fmt.Fprintln(rootCmd.ErrOrStderr(), "sekret: warning: no keys registered")
+	}
+
+	policy := newKeyPolicy(cfg, args[0])
+	if runExplain {
+		policy.explain()
+	}
+
+	env, values, cleanup, err := buildRunEnv(cfg, runEnvFile, policy)
+	if err != nil {
+		return err
+	}
+
+	if runViaProxy {
+		session, err := startProxy(cfg, "127.0.0.1:0", policy)
+		if err != nil {
+			cleanup()
+			return err
+		}
+		defer session.Close()
+		env = session.apply(env)
+	}
+
+	stdout, stderr := io.Writer(os.Stdout), io.Writer(os.Stderr)
+	flush := func() {}
+	if runRedact {
+		secrets := secretValues(slices.Concat(values.loaded, values.released))
+		out, errOut := redact.NewWriter(os.Stdout, secrets), redact.NewWriter(os.Stderr, secrets)
+		stdout, stderr = out, errOut
+		flush = func() {
+			_ = out.Flush()
+			_ = errOut.Flush()
+		}
+	}
+
+	recordAudit("read", args[0], auditKeys(values.released)...)
+	code, err := runChild(args, env, stdout, stderr)
+	flush()
+	cleanup()
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		exitFunc(code)
+	}
+	return nil
+}
+
+// buildRunEnv assembles the child environment: the current environment,
+// overlaid with registered keys, overlaid with the env file (if any).
+// sekret:// references are resolved last. Keys the policy withholds are
+// removed, and a reference to one is an error.
+//
+// File secrets are written to temporary files and their env vars point at
+// the paths. The returned cleanup function shreds those files and must be
+// called once the child has exited. The key values behind the environment
+// are returned alongside it.
+func buildRunEnv(cfg *config.Config, envFile string, policy *keyPolicy) ([]string, runValues, func(), error) {
+	env := os.Environ()
+	files := &secretFiles{}
+
+	values := runValues{loaded: loadKeyValues(cfg)}
+	for _, kv := range values.loaded {
+		d := policy.check(kv.envVar)
+		if !d.allowed {
+			env = unsetEnv(env, kv.envVar)
+			continue
+		}
+		values.released = append(values.released, kv)
+		if d.warn {
+			policy.warnViolation(kv.envVar, d)
+		}
+
+		value := kv.value
+		if kv.file {
+			path, err := files.write(kv.envVar, kv.value)
+			if err != nil {
+				files.cleanup()
+				return nil, runValues{}, nil, err
+			}
+			value = path
+		}
+		env = setEnv(env, kv.envVar, value)
+	}
+
+	if envFile != "" {
+		vars, err := envfile.Read(envFile)
+		if err != nil {
+			files.cleanup()
+			return nil, runValues{}, nil, fmt.Errorf("failed to read env file: %w", err)
+		}
+		for _, v := range vars {
+			env = setEnv(env, v.Key, v.Value)
+		}
+	}
+
+	env, refs, err := resolveRefs(cfg, env, policy, files)
+	if err != nil {
+		files.cleanup()
+		return nil, runValues{}, nil, err
+	}
+	for _, kv := range refs {
+		if !slices.ContainsFunc(values.released, func(r keyValue) bool { return r.envVar == kv.envVar }) {
+			values.released = append(values.released, kv)
+		}
+	}
+	return env, values, files.cleanup, nil
+}
+
+// runValues are the key values behind a run's environment.
+type runValues struct {
+	released []keyValue // passed to the command, directly or via sekret:// references
+	loaded   []keyValue // every registered key, including ones the policy withholds
+}
+
+// secretFiles materialises file secrets in a private temporary directory,
+// preferring $XDG_RUNTIME_DIR (usually tmpfs) over the system temp dir.
+type secretFiles struct {
+	dir string
+}
+
+// write stores content in a 0600 file named after the env var and returns its path.
+func (f *secretFiles) write(envVar, content string) (string, error) {
+	if f.dir == "" {
+		base := os.Getenv("XDG_RUNTIME_DIR")
+		if base == "" {
+			base = os.TempDir()
+		}
+		dir, err := os.MkdirTemp(base, "sekret-")
+		if err != nil {
+			return "", fmt.Errorf("failed to create directory for file secrets: %w", err)
+		}
+		f.dir = dir
+	}
+
+	path := filepath.Join(f.dir, envVar)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return "", fmt.Errorf("failed to write file secret %q: %w", envVar, err)
+	}
+	return path, nil
+}
+
+// cleanup overwrites each file with zeros before removing the directory.
+func (f *secretFiles) cleanup() {
+	if f.dir == "" {
+		return
+	}
+	entries, _ := os.ReadDir(f.dir)
+	for _, e := range entries {
+		shredFile(filepath.Join(f.dir, e.Name()))
+	}
+	if err := os.RemoveAll(f.dir); err != nil {
+		_, _ = fmt.Fprintf(rootCmd.ErrOrStderr(), "sekret: warning: could not remove %s: %v\n", f.dir, err)
+	}
+	f.dir = ""
+}
+
+// shredFile overwrites a file's content with zeros and flushes it to disk.
+func shredFile(path string) {
+	file, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return
+	}
+	defer func() { _ = file.Close() }()
+
+	if info, err := file.Stat(); err == nil {
+		_, _ = file.Write(make([]byte, info.Size()))
+		_ = file.Sync()
+	}
+}
+
+// resolveRefs replaces every sekret:// reference value in env with the
+// referenced key's value, or with a path to it for file secrets. An
+// unresolvable reference, or one to a key the policy withholds, is an error.
+// The referenced keys are returned with their values.
+func resolveRefs(cfg *config.Config, env []string, policy *keyPolicy, files *secretFiles) ([]string, []keyValue, error) {
+	resolved := make([]string, len(env))
+	var refs []keyValue
+	for i, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(value, refScheme) {
+			target := refTarget(cfg, value)
+			d := policy.check(target)
+			if !d.allowed {
+				return nil, nil, fmt.Errorf("policy forbids passing %s to %s via %s (%s)", target, policy.name, name, d.reason)
+			}
+			if d.warn {
+				policy.warnViolation(target, d)
+			}
+			val, err := resolveRef(cfg, value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to resolve %s for %s: %w", value, name, err)
+			}
+			entry := cfg.FindKeyByEnvVar(target)
+			refs = append(refs, keyValue{envVar: target, value: val, plain: entry != nil && entry.IsPlain()})
+			if entry != nil && entry.IsFile() {
+				if val, err = files.write(target, val); err != nil {
+					return nil, nil, err
+				}
+			}
+			value = val
+		}
+		resolved[i] = name + "=" + value
+	}
+	return resolved, refs, nil
+}
+
+// resolveRef reads the value behind a sekret://<ENV_VAR> reference.
+func resolveRef(cfg *config.Config, ref string) (string, error) {
+	arg := strings.TrimPrefix(ref, refScheme)
+	if arg == "" {
+		return "", fmt.Errorf("empty reference")
+	}
+	return readKeyValue(cfg, arg)
+}
+
+// refTarget returns the env var a sekret:// reference points at.
+func refTarget(cfg *config.Config, ref string) string {
+	return canonicalName(cfg, strings.TrimPrefix(ref, refScheme))
+}
+
+// unsetEnv removes any assignment of key from env.
+func unsetEnv(env []string, key string) []string {
+	prefix := key + "="
+	return slices.DeleteFunc(env, func(kv string) bool { return strings.HasPrefix(kv, prefix) })
+}
+
+// setEnv sets key=value in env, replacing any existing assignment of key.
+func setEnv(env []string, key, value string) []string {
+	prefix := key + "="
+	for i, kv := range env {
+		if strings.HasPrefix(kv, prefix) {
+			env[i] = prefix + value
+			return env
+		}
+	}
+	return append(env, prefix+value)
+}
+
+// runChild runs args as a child process with the given environment,
+// inheriting stdin, and returns the child's exit code.
+// A command that cannot be found yields 127, as in a shell.
+func runChild(args, env []string, stdout, stderr io.Writer) (int, error) {
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		_, _ = fmt.Fprintf(rootCmd.ErrOrStderr(), "sekret: command not found: %s\n", args[0])
+		return 127, nil
+	}
+
+	c := exec.Command(path, args[1:]...)
+	c.Env = env
+	c.Stdin = os.Stdin
+	c.Stdout = stdout
+	c.Stderr = stderr
+
+	// The child receives terminal signals directly; sekret only waits for it
+	// so the exit code can be passed through. SIGTERM and SIGHUP, which are
+	// usually sent to sekret alone, are forwarded, so that sekret outlives
+	// the child and can clean up after it.
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	if err := c.Start(); err != nil {
+		return 0, fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				if sig != os.Interrupt {
+					_ = c.Process.Signal(sig)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	if err := c.Wait(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			if code := exitErr.ExitCode(); code > 0 {
+				return code, nil
+			}
+			return 1, nil
+		}
+		return 0, fmt.Errorf("failed to run %s: %w", args[0], err)
+	}
+	return 0, nil
+}
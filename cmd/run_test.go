@@ -0,0 +1,166 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/eazyhozy/sekret/cmd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupRun configures the test environment and records the exit code.
+func setupRun(t *testing.T) *int {
+	t.Helper()
+	setup(t)
+	code := -1
+	cmd.SetExitFunc(func(c int) { code = c })
+	t.Cleanup(func() {
+		cmd.SetExitFunc(os.Exit)
+	})
+	return &code
+}
+
+func TestRun_InjectsKeys(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c", `printf %s "$OPENAI_API_KEY"`))
+	})
+
+	assert.Equal(t, "sk-test123", output)
+}
+
+func TestRun_ResolvesEnvFileReferences(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+
+	envFile := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envFile, []byte(`MY_KEY=sekret://OPENAI_API_KEY
+PLAIN_VALUE=hello
+`), 0o600))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--env-file", envFile, "--", "sh", "-c", `printf '%s %s' "$MY_KEY" "$PLAIN_VALUE"`))
+	})
+
+	assert.Equal(t, "sk-test123 hello", output)
+}
+
+func TestRun_ResolvesEnvironmentReferences(t *testing.T) {
+	setupRun(t)
+	seedKey(t, "GITHUB_TOKEN", "ghp_abc123")
+	t.Setenv("GH_TOKEN", "sekret://github")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c", `printf %s "$GH_TOKEN"`))
+	})
+
+	assert.Equal(t, "ghp_abc123", output)
+}
+
+func TestRun_UnknownReference(t *testing.T) {
+	setupRun(t)
+	t.Setenv("MY_KEY", "sekret://MISSING_KEY")
+
+	err := executeCmd(t, "run", "--", "true")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sekret://MISSING_KEY")
+	assert.Contains(t, err.Error(), "not registered")
+}
+
+func TestRun_NoCommand(t *testing.T) {
+	setupRun(t)
+
+	err := executeCmd(t, "run", "--")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no command specified")
+}
+
+func TestRun_ExitCodePassthrough(t *testing.T) {
+	code := setupRun(t)
+
+	require.NoError(t, executeCmd(t, "run", "--", "sh", "-c", "exit 3"))
+	assert.Equal(t, 3, *code)
+}
+
+func TestRun_CommandNotFound(t *testing.T) {
+	code := setupRun(t)
+
+	require.NoError(t, executeCmd(t, "run", "--", "sekret-no-such-command"))
+	assert.Equal(t, 127, *code)
+}
+
+func TestRun_FileSecretMaterialised(t *testing.T) {
+	setupRun(t)
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	seedFileKey(t, "GOOGLE_APPLICATION_CREDENTIALS", "-----BEGIN KEY-----\nabc\n-----END KEY-----\n")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c",
+			`printf '%s\n' "$GOOGLE_APPLICATION_CREDENTIALS"; stat -c %a "$GOOGLE_APPLICATION_CREDENTIALS" 2>/dev/null || stat -f %Lp "$GOOGLE_APPLICATION_CREDENTIALS"; cat "$GOOGLE_APPLICATION_CREDENTIALS"`))
+	})
+
+	lines := strings.SplitN(output, "\n", 3)
+	require.Len(t, lines, 3)
+	assert.True(t, strings.HasPrefix(lines[0], runtimeDir), "file should be created under XDG_RUNTIME_DIR")
+	assert.Equal(t, "600", lines[1])
+	assert.Equal(t, "-----BEGIN KEY-----\nabc\n-----END KEY-----\n", lines[2])
+
+	_, err := os.Stat(lines[0])
+	assert.True(t, os.IsNotExist(err), "file should be removed after the command exits")
+	entries, err := os.ReadDir(runtimeDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRun_FileSecretReference(t *testing.T) {
+	setupRun(t)
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	seedFileKey(t, "GOOGLE_APPLICATION_CREDENTIALS", "-----BEGIN KEY-----\nabc\n-----END KEY-----\n")
+	t.Setenv("GCP_KEY_FILE", "sekret://GOOGLE_APPLICATION_CREDENTIALS")
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c", `test -f "$GCP_KEY_FILE" && cat "$GCP_KEY_FILE"`))
+	})
+
+	assert.Equal(t, "-----BEGIN KEY-----\nabc\n-----END KEY-----\n", output)
+}
+
+func TestRun_FileSecretRemovedOnSIGTERM(t *testing.T) {
+	code := setupRun(t)
+	runtimeDir := t.TempDir()
+	t.Setenv("XDG_RUNTIME_DIR", runtimeDir)
+	seedFileKey(t, "GOOGLE_APPLICATION_CREDENTIALS", "-----BEGIN KEY-----\nabc\n-----END KEY-----\n")
+	started := filepath.Join(t.TempDir(), "started")
+
+	done := make(chan error, 1)
+	go func() {
+		done <- executeCmd(t, "run", "--", "sh", "-c", `touch "$1"; exec sleep 30`, "sh", started)
+	}()
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(started)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	self, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, self.Signal(syscall.SIGTERM))
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("sekret run did not forward SIGTERM to the command")
+	}
+
+	assert.Equal(t, 1, *code)
+	entries, err := os.ReadDir(runtimeDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "file secrets should be removed")
+}
@@ -26,7 +26,9 @@ var scanCmd = &cobra.Command{
 	Long: `Scan shell config files for plaintext API keys in export statements.
 
 By default, scans ~/.zshrc, ~/.zshenv, ~/.zprofile, ~/.bashrc,
-~/.bash_profile, and ~/.profile.
+~/.bash_profile, and ~/.profile, plus plaintext credential files
+such as ~/.aws/credentials. Keys of AWS profiles other than [default]
+are reported with the profile as a suffix, e.g. AWS_SECRET_ACCESS_KEY_WORK.
 
 Use --path to scan a specific file or directory instead.`,
 	Args: cobra.NoArgs,
@@ -128,17 +130,35 @@ func resolveScanTargets(path string) ([]string, error) {
 		if targets == nil {
 			return nil, fmt.Errorf("could not determine home directory")
 		}
-		return targets, nil
+		return append(targets, scanner.CredentialTargets()...), nil
 	}
 	return scanner.ResolvePath(path)
 }
 
 // annotate returns a status annotation for a finding based on sekret state.
 func annotate(cfg *config.Config, f scanner.Finding) string {
+	if multi := cfg.FindKeyByField(f.EnvVar); multi != nil {
+		stored, err := newValueResolver(cfg).lookup(f.EnvVar)
+		switch {
+		case err != nil:
+			return "field of " + multi.EnvVar + " in sekret"
+		case stored == f.Value:
+			return "field of " + multi.EnvVar + " in sekret, safe to remove"
+		default:
+			return "field of " + multi.EnvVar + " in sekret, value differs!"
+		}
+	}
+
 	entry := cfg.FindKeyByEnvVar(f.EnvVar)
 	if entry == nil {
 		return ""
 	}
+	if entry.IsDerived() {
+		return "derived in sekret"
+	}
+	if entry.IsPlain() {
+		return "plain variable in sekret"
+	}
 
 	keychainKey := entry.KeychainKey()
 	stored, err := store.Get(keychainKey)
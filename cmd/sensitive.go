@@ -0,0 +1,258 @@
+package cmd
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/grant"
+	"github.com/spf13/cobra"
+)
+
+// passphraseKey is the keychain item holding the passphrase hash. The colon
+// keeps it from clashing with env var names.
+const passphraseKey = "sekret:passphrase"
+
+// passphraseIterations is the PBKDF2-SHA256 work factor.
+const passphraseIterations = 600_000
+
+var (
+	sensitiveOff bool
+	sensitiveTTL string
+
+	passphraseRemove bool
+)
+
+var sensitiveCmd = &cobra.Command{
+	Use:   "sensitive [ENV_VAR...]",
+	Short: "Require confirmation before sensitive keys are released",
+	Long: `Mark keys as sensitive. Reading a sensitive key through env, get, run or
+any other command asks for confirmation on the terminal, or for the sekret
+passphrase if one is set (see 'sekret passphrase'). Without a terminal the
+key is withheld.
+
+  sekret sensitive PROD_DEPLOY_TOKEN
+  sekret sensitive PROD_DEPLOY_TOKEN --off
+
+Once released, a key stays unlocked in the same terminal session for the
+grant duration (15m by default; 0 asks every time):
+
+  sekret sensitive --ttl 1h
+
+'sekret lock' revokes all grants of the current session.`,
+	RunE: runSensitive,
+}
+
+var passphraseCmd = &cobra.Command{
+	Use:   "passphrase",
+	Short: "Set the passphrase that releases sensitive keys",
+	Args:  cobra.NoArgs,
+	RunE:  runPassphrase,
+}
+
+var lockCmd = &cobra.Command{
+	Use:   "lock",
+	Short: "Revoke sensitive key grants of the current terminal session",
+	Args:  cobra.NoArgs,
+	RunE:  runLock,
+}
+
+func init() {
+	sensitiveCmd.Flags().BoolVar(&sensitiveOff, "off", false, "stop treating the keys as sensitive")
+	sensitiveCmd.Flags().StringVar(&sensitiveTTL, "ttl", "", "how long a released key stays unlocked, e.g. 15m")
+	passphraseCmd.Flags().BoolVar(&passphraseRemove, "remove", false, "remove the passphrase and confirm releases instead")
+	rootCmd.AddCommand(sensitiveCmd, passphraseCmd, lockCmd)
+}
+
+// unlockSensitive asks the user to release a sensitive key, unless a grant
+// for it is still valid in this session. It asks on /dev/tty, never stdin,
+// which may carry data another program controls (an MCP client, a piped
+// log); without a terminal the key is not released.
+func unlockSensitive(cfg *config.Config, entry *config.KeyEntry) error {
+	dir, err := config.Dir()
+	if err != nil {
+		return err
+	}
+	grants := grant.Open(dir)
+	if grants.Valid(entry.EnvVar) {
+		return nil
+	}
+
+	if hash, err := store.Get(passphraseKey); err == nil {
+		pass, err := readTTYPassword(fmt.Sprintf("  Passphrase to release %s: ", entry.EnvVar))
+		if err != nil {
+			return fmt.Errorf("sensitive key %q was not released: %w", entry.EnvVar, err)
+		}
+		if !verifyPassphrase(hash, pass) {
+			return fmt.Errorf("sensitive key %q was not released: wrong passphrase", entry.EnvVar)
+		}
+	} else {
+		ok, err := readApproval(fmt.Sprintf("  Release sensitive key %s? [y/N]: ", entry.EnvVar))
+		if err != nil {
+			return fmt.Errorf("sensitive key %q was not released: %w", entry.EnvVar, err)
+		}
+		if !ok {
+			return fmt.Errorf("sensitive key %q was not released", entry.EnvVar)
+		}
+	}
+
+	if err := grants.Grant(entry.EnvVar, cfg.SensitiveGrantTTL()); err != nil {
+		_, _ = fmt.Fprintf(rootCmd.ErrOrStderr(), "sekret: warning: could not save grant: %v\n", err)
+	}
+	return nil
+}
+
+// hashPassphrase returns a salted PBKDF2 hash in the form
+// pbkdf2-sha256$<iterations>$<salt>$<hash>.
+func hashPassphrase(pass string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+	key, err := pbkdf2.Key(sha256.New, pass, salt, passphraseIterations, 32)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash passphrase: %w", err)
+	}
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", passphraseIterations, enc.EncodeToString(salt), enc.EncodeToString(key)), nil
+}
+
+// verifyPassphrase checks pass against a hash from hashPassphrase.
+func verifyPassphrase(hash, pass string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 {
+		return false
+	}
+	enc := base64.RawStdEncoding
+	salt, err1 := enc.DecodeString(parts[2])
+	want, err2 := enc.DecodeString(parts[3])
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, pass, salt, iter, len(want))
+	return err == nil && subtle.ConstantTimeCompare(got, want) == 1
+}
+
+func runSensitive(c *cobra.Command, args []string) error {
+	if len(args) == 0 && sensitiveTTL == "" {
+		return fmt.Errorf("specify keys to mark, or --ttl")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if sensitiveTTL != "" {
+		d, err := time.ParseDuration(sensitiveTTL)
+		if err != nil || d < 0 {
+			return fmt.Errorf("invalid --ttl %q: use a duration like 15m or 1h", sensitiveTTL)
+		}
+		cfg.GrantTTL = sensitiveTTL
+	}
+
+	entries := make([]*config.KeyEntry, 0, len(args))
+	for _, arg := range args {
+		entry, err := resolveKey(cfg, arg)
+		if err != nil {
+			return err
+		}
+		if entry.IsPlain() {
+			return fmt.Errorf("%s is a plain variable and cannot be sensitive", entry.EnvVar)
+		}
+		entries = append(entries, entry)
+	}
+	for _, entry := range entries {
+		entry.Sensitive = !sensitiveOff
+	}
+
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if sensitiveOff {
+			_, _ = fmt.Fprintf(c.ErrOrStderr(), "  %s is no longer sensitive\n", entry.EnvVar)
+		} else {
+			_, _ = fmt.Fprintf(c.ErrOrStderr(), "  %s is now sensitive\n", entry.EnvVar)
+		}
+	}
+	if sensitiveTTL != "" {
+		_, _ = fmt.Fprintf(c.ErrOrStderr(), "  Released keys stay unlocked for %s\n", sensitiveTTL)
+	}
+	return nil
+}
+
+func runPassphrase(c *cobra.Command, _ []string) error {
+	hash, err := store.Get(passphraseKey)
+	hasPassphrase := err == nil
+	if hasPassphrase {
+		current, err := readPassword("  Current passphrase: ")
+		if err != nil {
+			return err
+		}
+		if !verifyPassphrase(hash, current) {
+			return fmt.Errorf("wrong passphrase")
+		}
+	}
+
+	if passphraseRemove {
+		if !hasPassphrase {
+			return fmt.Errorf("no passphrase is set")
+		}
+		if err := store.Delete(passphraseKey); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(c.ErrOrStderr(), "  Passphrase removed; sensitive keys now ask for confirmation")
+		return nil
+	}
+
+	pass, err := readPassword("  New passphrase: ")
+	if err != nil {
+		return err
+	}
+	if len(pass) < 8 {
+		return fmt.Errorf("passphrase must be at least 8 characters")
+	}
+	again, err := readPassword("  Repeat passphrase: ")
+	if err != nil {
+		return err
+	}
+	if pass != again {
+		return fmt.Errorf("passphrases do not match")
+	}
+
+	newHash, err := hashPassphrase(pass)
+	if err != nil {
+		return err
+	}
+	if err := store.Set(passphraseKey, newHash); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintln(c.ErrOrStderr(), "  Passphrase saved to OS keychain")
+	return nil
+}
+
+func runLock(c *cobra.Command, _ []string) error {
+	dir, err := config.Dir()
+	if err != nil {
+		return err
+	}
+	if err := grant.Open(dir).Revoke(); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(c.ErrOrStderr(), "  Sensitive keys locked")
+	return nil
+}
@@ -0,0 +1,155 @@
+package cmd_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eazyhozy/sekret/cmd"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// confirmWith answers every confirmation with answer and counts the prompts.
+func confirmWith(answer bool) *int {
+	count := 0
+	cmd.SetReadConfirm(func(_ string) (bool, error) {
+		count++
+		return answer, nil
+	})
+	return &count
+}
+
+func seedSensitiveKey(t *testing.T, envVar, value string) {
+	t.Helper()
+	seedKey(t, envVar, value)
+	require.NoError(t, executeCmd(t, "sensitive", envVar))
+}
+
+func TestSensitive_MarkAndUnmark(t *testing.T) {
+	setup(t)
+	seedKey(t, "DEPLOY_TOKEN", "tok-123")
+
+	require.NoError(t, executeCmd(t, "sensitive", "DEPLOY_TOKEN", "--ttl", "1h"))
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	assert.True(t, cfg.FindKeyByEnvVar("DEPLOY_TOKEN").Sensitive)
+	assert.Equal(t, "1h", cfg.GrantTTL)
+
+	require.NoError(t, executeCmd(t, "sensitive", "DEPLOY_TOKEN", "--off"))
+	cfg, err = config.Load()
+	require.NoError(t, err)
+	assert.False(t, cfg.FindKeyByEnvVar("DEPLOY_TOKEN").Sensitive)
+
+	assert.Error(t, executeCmd(t, "sensitive", "--ttl", "soon"))
+}
+
+func TestSensitive_GetRequiresConfirmation(t *testing.T) {
+	setup(t)
+	seedSensitiveKey(t, "DEPLOY_TOKEN", "tok-123")
+	approveWith(false)
+
+	err := executeCmd(t, "get", "DEPLOY_TOKEN")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not released")
+
+	prompts := approveWith(true)
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "DEPLOY_TOKEN"))
+	})
+	assert.Equal(t, "tok-123\n", output)
+	assert.Len(t, *prompts, 1)
+}
+
+func TestSensitive_GrantIsCached(t *testing.T) {
+	setup(t)
+	seedSensitiveKey(t, "DEPLOY_TOKEN", "tok-123")
+	prompts := approveWith(true)
+
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "DEPLOY_TOKEN"))
+		require.NoError(t, executeCmd(t, "get", "DEPLOY_TOKEN"))
+	})
+	assert.Len(t, *prompts, 1, "second read uses the grant")
+
+	require.NoError(t, executeCmd(t, "lock"))
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "DEPLOY_TOKEN"))
+	})
+	assert.Len(t, *prompts, 2, "lock revokes the grant")
+}
+
+func TestSensitive_ZeroTTLAsksEveryTime(t *testing.T) {
+	setup(t)
+	seedSensitiveKey(t, "DEPLOY_TOKEN", "tok-123")
+	require.NoError(t, executeCmd(t, "sensitive", "--ttl", "0"))
+	prompts := approveWith(true)
+
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "DEPLOY_TOKEN"))
+		require.NoError(t, executeCmd(t, "get", "DEPLOY_TOKEN"))
+	})
+	assert.Len(t, *prompts, 2)
+}
+
+func TestSensitive_EnvSkipsDeniedKey(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-test123")
+	seedSensitiveKey(t, "DEPLOY_TOKEN", "tok-123")
+	approveWith(false)
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "env"))
+	})
+
+	assert.Contains(t, output, "OPENAI_API_KEY")
+	assert.NotContains(t, output, "tok-123")
+}
+
+func TestSensitive_RunRequiresConfirmation(t *testing.T) {
+	setupRun(t)
+	seedSensitiveKey(t, "DEPLOY_TOKEN", "tok-123")
+	approveWith(true)
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "run", "--", "sh", "-c", `printf %s "$DEPLOY_TOKEN"`))
+	})
+
+	assert.Equal(t, "tok-123", output)
+}
+
+func TestSensitive_Passphrase(t *testing.T) {
+	setup(t)
+	seedSensitiveKey(t, "DEPLOY_TOKEN", "tok-123")
+	cmd.SetReadPassword(passwordSequence("correct horse", "correct horse"))
+	require.NoError(t, executeCmd(t, "passphrase"))
+
+	cmd.SetReadTTYPassword(passwordSequence("wrong guess"))
+	err := executeCmd(t, "get", "DEPLOY_TOKEN")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "wrong passphrase")
+
+	cmd.SetReadTTYPassword(passwordSequence("correct horse"))
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "get", "DEPLOY_TOKEN"))
+	})
+	assert.Equal(t, "tok-123\n", output)
+
+	cmd.SetReadPassword(passwordSequence("wrong guess"))
+	assert.Error(t, executeCmd(t, "passphrase", "--remove"), "removal needs the current passphrase")
+	cmd.SetReadPassword(passwordSequence("correct horse"))
+	require.NoError(t, executeCmd(t, "passphrase", "--remove"))
+}
+
+func TestSensitive_StdinCannotRelease(t *testing.T) {
+	setup(t)
+	seedSensitiveKey(t, "DEPLOY_TOKEN", "tok-123")
+	confirmWith(true) // a "y" arriving on stdin
+	cmd.SetReadApproval(func(_ string) (bool, error) {
+		return false, fmt.Errorf("no terminal available for approval")
+	})
+
+	err := executeCmd(t, "get", "DEPLOY_TOKEN")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not released")
+}
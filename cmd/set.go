@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/eazyhozy/sekret/internal/config"
 	"github.com/eazyhozy/sekret/internal/registry"
@@ -10,14 +11,33 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	setTemplate string
+	setPlain    string
+	setFile     string
+	setOverlap  time.Duration
+)
+
 var setCmd = &cobra.Command{
 	Use:   "set <ENV_VAR>",
 	Short: "Update an existing API key",
-	Args:  cobra.ExactArgs(1),
-	RunE:  runSet,
+	Long: `Update an existing key. The replaced value is kept in the key's history
+(see 'sekret history' and 'sekret rollback').
+
+During a rotation, --overlap also exports the replaced value as
+<ENV_VAR>_PREVIOUS for the given time, so services can accept both:
+
+  sekret set STRIPE_WEBHOOK_SECRET --overlap 24h`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSet,
 }
 
 func init() {
+	setCmd.Flags().StringVar(&setTemplate, "template", "", "update the template of a derived variable")
+	setCmd.Flags().StringVar(&setPlain, "plain", "", "update the value of a plain variable")
+	setCmd.Flags().StringVar(&setFile, "file", "", "update a file secret with the content of this file")
+	setCmd.Flags().DurationVar(&setOverlap, "overlap", 0, "also export the replaced value as <ENV_VAR>_PREVIOUS for this long")
+	setCmd.MarkFlagsMutuallyExclusive("template", "plain", "file")
 	rootCmd.AddCommand(setCmd)
 }
 
@@ -34,6 +54,26 @@ func runSet(_ *cobra.Command, args []string) error {
 		return fmt.Errorf("key %q is not registered (use 'sekret add %s' first)", arg, arg)
 	}
 
+	if setOverlap != 0 && (!entry.InKeychain() || entry.IsFile() || entry.IsMulti() || setFile != "") {
+		return fmt.Errorf("--overlap is only supported for single-value keychain keys")
+	}
+	if setOverlap < 0 {
+		return fmt.Errorf("--overlap must not be negative")
+	}
+
+	if entry.IsDerived() || setTemplate != "" {
+		return setDerived(cfg, entry, setTemplate)
+	}
+	if entry.IsPlain() || setPlain != "" {
+		return setPlainVar(cfg, entry, setPlain)
+	}
+	if entry.IsFile() || setFile != "" {
+		return setFileSecret(cfg, entry, setFile)
+	}
+	if entry.IsMulti() {
+		return setMulti(cfg, entry)
+	}
+
 	keychainKey := entry.KeychainKey()
 
 	// Show current masked value
@@ -59,11 +99,121 @@ func runSet(_ *cobra.Command, args []string) error {
 			entry.EnvVar, strings.Join(regEntry.Prefixes, " or "))
 	}
 
-	// Update keychain
-	if err := store.Set(keychainKey, value); err != nil {
+	// Update keychain, keeping the old value in history
+	if setOverlap > 0 {
+		entry.PreviousUntil = time.Now().Add(setOverlap)
+	}
+	if err := replaceValue(cfg, entry, value); err != nil {
+		return err
+	}
+
+	recordAudit("set", "", entry.EnvVar)
+	_, _ = fmt.Fprintln(rootCmd.ErrOrStderr(), "  Updated")
+	return nil
+}
+
+// setDerived replaces the template of a derived variable.
+func setDerived(cfg *config.Config, entry *config.KeyEntry, tmpl string) error {
+	if !entry.IsDerived() {
+		return fmt.Errorf("%q is stored in the keychain, not derived (remove it and add it with --template)", entry.EnvVar)
+	}
+	if tmpl == "" {
+		return fmt.Errorf("%q is derived from a template (use --template to change it)", entry.EnvVar)
+	}
+	if err := validateTemplate(cfg, entry.EnvVar, tmpl); err != nil {
+		return err
+	}
+
+	entry.Template = tmpl
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+
+	recordAudit("set", "", entry.EnvVar)
+	_, _ = fmt.Fprintln(rootCmd.ErrOrStderr(), "  Updated")
+	return nil
+}
+
+// setPlainVar replaces the value of a plain variable.
+func setPlainVar(cfg *config.Config, entry *config.KeyEntry, value string) error {
+	if !entry.IsPlain() {
+		return fmt.Errorf("%q is a secret (plain values can only be set on plain variables)", entry.EnvVar)
+	}
+	if value == "" {
+		return fmt.Errorf("%q is a plain variable (use --plain to change it)", entry.EnvVar)
+	}
+
+	entry.Value = value
+	if err := saveConfig(cfg); err != nil {
+		return err
+	}
+
+	recordAudit("set", "", entry.EnvVar)
+	_, _ = fmt.Fprintln(rootCmd.ErrOrStderr(), "  Updated")
+	return nil
+}
+
+// setFileSecret replaces the content of a file secret.
+func setFileSecret(cfg *config.Config, entry *config.KeyEntry, path string) error {
+	if !entry.IsFile() {
+		return fmt.Errorf("%q is not a file secret", entry.EnvVar)
+	}
+	if path == "" {
+		return fmt.Errorf("%q is a file secret (use --file to change it)", entry.EnvVar)
+	}
+
+	content, err := readSecretFile(path)
+	if err != nil {
+		return err
+	}
+	if err := replaceValue(cfg, entry, content); err != nil {
+		return err
+	}
+
+	recordAudit("set", "", entry.EnvVar)
+	_, _ = fmt.Fprintln(rootCmd.ErrOrStderr(), "  Updated")
+	return nil
+}
+
+// setMulti updates the fields of a multi-field credential in one keychain
+// write. Pressing Enter keeps a field's current value.
+func setMulti(cfg *config.Config, entry *config.KeyEntry) error {
+	current := map[string]string{}
+	if raw, err := store.Get(entry.KeychainKey()); err == nil {
+		if fields, err := decodeFields(raw); err == nil {
+			current = fields
+		}
+	}
+
+	values := map[string]string{}
+	for _, f := range entry.Fields {
+		prompt := fmt.Sprintf("  %s: ", f)
+		if v, ok := current[f]; ok {
+			prompt = fmt.Sprintf("  %s [%s] (Enter to keep): ", f, scanner.MaskValue(v))
+		}
+		v, err := readPassword(prompt)
+		if err != nil {
+			return err
+		}
+		if v = strings.TrimSpace(v); v != "" {
+			values[f] = v
+		} else if old, ok := current[f]; ok {
+			values[f] = old
+		}
+	}
+	if len(values) == 0 {
+		return fmt.Errorf("at least one field must be set")
+	}
+
+	encoded, err := encodeFields(values)
+	if err != nil {
+		return err
+	}
+	if err := replaceValue(cfg, entry, encoded); err != nil {
 		return err
 	}
 
+	recordAudit("set", "", entry.EnvVar)
 	_, _ = fmt.Fprintln(rootCmd.ErrOrStderr(), "  Updated")
 	return nil
 }
@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/eazyhozy/sekret/internal/registry"
+	"github.com/spf13/cobra"
+)
+
+var trashCmd = &cobra.Command{
+	Use:   "trash",
+	Short: "Manage removed keys",
+	Long: `'sekret remove' moves keys to the trash instead of deleting them. Trashed
+keys are kept in a separate keychain namespace and can be brought back with
+'sekret restore' until they are purged, 30 days after removal by default
+(set trash_retention in the config, e.g. "168h"). Expired keys are purged
+by the next sekret command run after that.`,
+}
+
+var trashListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List removed keys",
+	Args:  cobra.NoArgs,
+	RunE:  runTrashList,
+}
+
+var trashPurgeCmd = &cobra.Command{
+	Use:   "purge [ENV_VAR...]",
+	Short: "Permanently delete removed keys (all of them if none are given)",
+	RunE:  runTrashPurge,
+}
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore <ENV_VAR>",
+	Short: "Restore a removed key from the trash",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runRestore,
+}
+
+func init() {
+	trashCmd.AddCommand(trashListCmd, trashPurgeCmd)
+	rootCmd.AddCommand(trashCmd, restoreCmd)
+}
+
+// moveItem moves a keychain item to a new name.
+func moveItem(from, to string) error {
+	value, err := store.Get(from)
+	if err != nil {
+		return err
+	}
+	if err := store.Set(to, value); err != nil {
+		return err
+	}
+	return store.Delete(from)
+}
+
+// trashKey moves entry and its keychain items to the trash, replacing an
+// earlier trashed key with the same env var. The caller saves the config.
+func trashKey(cfg *config.Config, entry *config.KeyEntry) error {
+	removed := *entry
+	if old := cfg.FindTrashed(removed.EnvVar); old != nil {
+		purgeTrashed(old)
+		cfg.RemoveTrashed(removed.EnvVar)
+	}
+
+	if removed.InKeychain() {
+		if err := moveItem(removed.KeychainKey(), config.TrashItem(removed.KeychainKey())); err != nil {
+			return err
+		}
+	}
+	for _, v := range removed.History {
+		_ = moveItem(removed.HistoryKey(v.Seq), config.TrashItem(removed.HistoryKey(v.Seq)))
+	}
+
+	if err := cfg.RemoveKey(removed.EnvVar); err != nil {
+		return err
+	}
+	cfg.Trash = append(cfg.Trash, config.TrashedKey{Entry: removed, RemovedAt: time.Now()})
+	return nil
+}
+
+// purgeTrashed deletes the keychain items of a trashed key.
+func purgeTrashed(t *config.TrashedKey) {
+	for _, item := range t.Entry.KeychainItems() {
+		_ = store.Delete(config.TrashItem(item))
+	}
+}
+
+// expired reports whether t is older than the retention period.
+func expired(cfg *config.Config, t *config.TrashedKey) bool {
+	return t.RemovedAt.Before(time.Now().Add(-cfg.TrashRetentionPeriod()))
+}
+
+// purgeExpiredTrash permanently deletes trashed keys older than the
+// retention period. It runs before every command, so an expired key does
+// not linger in the keychain whichever commands are used. A failure is
+// reported but does not stop the command.
+func purgeExpiredTrash(c *cobra.Command) {
+	if c == configCmd || c.Parent() == configCmd {
+		return
+	}
+	cfg, err := config.Load()
+	if err != nil || !slices.ContainsFunc(cfg.Trash, func(t config.TrashedKey) bool { return expired(cfg, &t) }) {
+		return
+	}
+	err = transaction("purge", func() error {
+		for i := range cfg.Trash {
+			if expired(cfg, &cfg.Trash[i]) {
+				purgeTrashed(&cfg.Trash[i])
+			}
+		}
+		cfg.Trash = slices.DeleteFunc(cfg.Trash, func(t config.TrashedKey) bool { return expired(cfg, &t) })
+		return config.Save(cfg)
+	})
+	if err != nil {
+		_, _ = fmt.Fprintf(c.ErrOrStderr(), "sekret: warning: could not purge expired keys from the trash: %v\n", err)
+	}
+}
+
+// findTrashed resolves arg, an env var or registry shorthand, to a trashed key.
+func findTrashed(cfg *config.Config, arg string) (*config.TrashedKey, error) {
+	if t := cfg.FindTrashed(arg); t != nil {
+		return t, nil
+	}
+	if regEntry := registry.Lookup(arg); regEntry != nil {
+		if t := cfg.FindTrashed(regEntry.EnvVar); t != nil {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("key %q is not in the trash", arg)
+}
+
+func runTrashList(c *cobra.Command, _ []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	if len(cfg.Trash) == 0 {
+		_, _ = fmt.Fprintln(c.ErrOrStderr(), "Trash is empty.")
+		return nil
+	}
+
+	retention := cfg.TrashRetentionPeriod()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', 0)
+	_, _ = fmt.Fprintln(w, "Env Variable\tRemoved\tPurged")
+	_, _ = fmt.Fprintln(w, "------------\t-------\t------")
+	for _, t := range cfg.Trash {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", t.Entry.EnvVar,
+			humanize.Time(t.RemovedAt), humanize.Time(t.RemovedAt.Add(retention)))
+	}
+	return w.Flush()
+}
+
+func runTrashPurge(c *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	var targets []config.TrashedKey
+	if len(args) == 0 {
+		targets = slices.Clone(cfg.Trash) // RemoveTrashed below shifts cfg.Trash
+	} else {
+		for _, arg := range args {
+			t, err := findTrashed(cfg, arg)
+			if err != nil {
+				return err
+			}
+			targets = append(targets, *t)
+		}
+	}
+	if len(targets) == 0 {
+		_, _ = fmt.Fprintln(c.ErrOrStderr(), "Trash is empty.")
+		return nil
+	}
+
+	confirmed, err := readConfirm(fmt.Sprintf("  Permanently delete %d key(s)? [y/N]: ", len(targets)))
+	if err != nil {
+		return err
+	}
+	if !confirmed {
+		_, _ = fmt.Fprintln(c.ErrOrStderr(), "  Cancelled")
+		return nil
+	}
+
+	err = transaction("purge", func() error {
+		for i := range targets {
+			purgeTrashed(&targets[i])
+			cfg.RemoveTrashed(targets[i].Entry.EnvVar)
+		}
+		return config.Save(cfg)
+	})
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(c.ErrOrStderr(), "  Purged %d key(s)\n", len(targets))
+	return nil
+}
+
+func runRestore(c *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return err
+	}
+
+	t, err := findTrashed(cfg, args[0])
+	if err != nil {
+		return err
+	}
+	entry := t.Entry
+	if cfg.FindKeyByEnvVar(entry.EnvVar) != nil {
+		return fmt.Errorf("%s is registered again; remove it before restoring the old key", entry.EnvVar)
+	}
+	err = transaction("restore", func() error {
+		if err := cfg.AddEntry(entry); err != nil {
+			return err
+		}
+		if entry.InKeychain() {
+			if err := moveItem(config.TrashItem(entry.KeychainKey()), entry.KeychainKey()); err != nil {
+				return err
+			}
+		}
+		for _, v := range entry.History {
+			_ = moveItem(config.TrashItem(entry.HistoryKey(v.Seq)), entry.HistoryKey(v.Seq))
+		}
+		cfg.RemoveTrashed(entry.EnvVar)
+		return config.Save(cfg)
+	})
+	if err != nil {
+		return err
+	}
+
+	recordAudit("restore", "", entry.EnvVar)
+	_, _ = fmt.Fprintf(c.ErrOrStderr(), "  Restored %s\n", entry.EnvVar)
+	return nil
+}
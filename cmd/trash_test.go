@@ -0,0 +1,158 @@
+package cmd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemove_MovesToTrash(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-to-delete")
+	confirmWith(true)
+
+	require.NoError(t, executeCmd(t, "remove", "OPENAI_API_KEY"))
+
+	val, err := testStore.Get("trash:OPENAI_API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-to-delete", val)
+
+	cfg, _ := config.Load()
+	require.NotNil(t, cfg.FindTrashed("OPENAI_API_KEY"))
+}
+
+func TestTrashList(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-to-delete")
+	confirmWith(true)
+	require.NoError(t, executeCmd(t, "remove", "OPENAI_API_KEY"))
+
+	output := captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "trash", "list"))
+	})
+	assert.Contains(t, output, "OPENAI_API_KEY")
+}
+
+func TestRestore(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-to-delete")
+	confirmWith(true)
+	require.NoError(t, executeCmd(t, "remove", "OPENAI_API_KEY"))
+
+	require.NoError(t, executeCmd(t, "restore", "openai"))
+
+	val, err := testStore.Get("OPENAI_API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-to-delete", val)
+	_, err = testStore.Get("trash:OPENAI_API_KEY")
+	assert.Error(t, err)
+
+	cfg, _ := config.Load()
+	assert.NotNil(t, cfg.FindKeyByEnvVar("OPENAI_API_KEY"))
+	assert.Empty(t, cfg.Trash)
+}
+
+func TestRestore_ConflictsWithNewKey(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-old")
+	confirmWith(true)
+	require.NoError(t, executeCmd(t, "remove", "OPENAI_API_KEY"))
+	seedKey(t, "OPENAI_API_KEY", "sk-new")
+
+	err := executeCmd(t, "restore", "OPENAI_API_KEY")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "registered again")
+}
+
+func TestRestore_NotInTrash(t *testing.T) {
+	setup(t)
+
+	err := executeCmd(t, "restore", "OPENAI_API_KEY")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not in the trash")
+}
+
+func TestTrash_PurgesAfterRetention(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-to-delete")
+	confirmWith(true)
+	require.NoError(t, executeCmd(t, "remove", "OPENAI_API_KEY"))
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.Trash[0].RemovedAt = time.Now().Add(-config.DefaultTrashRetention - time.Hour)
+	require.NoError(t, config.Save(cfg))
+
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "trash", "list"))
+	})
+
+	_, err = testStore.Get("trash:OPENAI_API_KEY")
+	assert.Error(t, err)
+	cfg, _ = config.Load()
+	assert.Empty(t, cfg.Trash)
+}
+
+func TestTrash_PurgedByAnyCommand(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-to-delete")
+	seedKey(t, "GITHUB_TOKEN", "ghp_to_keep")
+	confirmWith(true)
+	require.NoError(t, executeCmd(t, "remove", "OPENAI_API_KEY"))
+	require.NoError(t, executeCmd(t, "remove", "GITHUB_TOKEN"))
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	cfg.Trash[0].RemovedAt = time.Now().Add(-config.DefaultTrashRetention - time.Hour)
+	require.NoError(t, config.Save(cfg))
+
+	captureStdout(t, func() {
+		require.NoError(t, executeCmd(t, "env"))
+	})
+
+	_, err = testStore.Get("trash:OPENAI_API_KEY")
+	assert.Error(t, err)
+	_, err = testStore.Get("trash:GITHUB_TOKEN")
+	assert.NoError(t, err)
+	cfg, _ = config.Load()
+	require.Len(t, cfg.Trash, 1)
+	assert.Equal(t, "GITHUB_TOKEN", cfg.Trash[0].Entry.EnvVar)
+}
+
+func TestTrashPurge(t *testing.T) {
+	setup(t)
+	seedKey(t, "OPENAI_API_KEY", "sk-to-delete")
+	confirmWith(true)
+	require.NoError(t, executeCmd(t, "remove", "OPENAI_API_KEY"))
+
+	require.NoError(t, executeCmd(t, "trash", "purge", "OPENAI_API_KEY"))
+
+	_, err := testStore.Get("trash:OPENAI_API_KEY")
+	assert.Error(t, err)
+	cfg, _ := config.Load()
+	assert.Empty(t, cfg.Trash)
+}
+
+func TestTrashPurge_All(t *testing.T) {
+	setup(t)
+	envVars := []string{"OPENAI_API_KEY", "GITHUB_TOKEN", "ANTHROPIC_API_KEY"}
+	for _, envVar := range envVars {
+		seedKey(t, envVar, "value-of-"+envVar)
+	}
+	confirmWith(true)
+	for _, envVar := range envVars {
+		require.NoError(t, executeCmd(t, "remove", envVar))
+	}
+
+	require.NoError(t, executeCmd(t, "trash", "purge"))
+
+	for _, envVar := range envVars {
+		_, err := testStore.Get("trash:" + envVar)
+		assert.Error(t, err, envVar)
+	}
+	cfg, _ := config.Load()
+	assert.Empty(t, cfg.Trash)
+}
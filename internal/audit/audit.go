@@ -0,0 +1,264 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/eazyhozy/sekret/internal/fsutil"
+)
+
+// LogFile is the audit log's name within the config directory.
+const LogFile = "audit.log"
+
+// headSuffix names the head record kept beside the log, e.g. audit.log.head.
+const headSuffix = ".head"
+
+// tailSize bounds how much of the log is read to find the last event.
+const tailSize = 64 * 1024
+
+// Event is one line of the audit log.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Op      string    `json:"op"` // add, set, rollback, remove, restore, import or read
+	Keys    []string  `json:"keys,omitempty"`
+	Command string    `json:"command"`          // sekret subcommand, e.g. "run"
+	Target  string    `json:"target,omitempty"` // program that received the keys
+	PID     int       `json:"pid"`              // calling process
+	Exe     string    `json:"exe,omitempty"`    // calling executable, if known
+	Profile string    `json:"profile"`
+
+	// Prev is the hash of the previous event; Hash covers Prev and every
+	// other field, chaining the log so edits and deletions are detectable.
+	Prev string `json:"prev"`
+	Hash string `json:"hash"`
+}
+
+// head records how many events the log holds and the hash of the last one,
+// so that events cut off the end of the log are detected.
+type head struct {
+	Count int    `json:"count"`
+	Hash  string `json:"hash"`
+}
+
+// computeHash returns the chain hash of e (ignoring e.Hash).
+func computeHash(e Event) (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Path returns the audit log path in dir.
+func Path(dir string) string {
+	return filepath.Join(dir, LogFile)
+}
+
+// Append chains e onto the log at path and writes it. Time is set to now
+// if zero. The log is locked while appending, so that concurrent sekret
+// processes do not chain two events onto the same predecessor.
+func Append(path string, e Event) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := lock(f); err != nil {
+		return fmt.Errorf("failed to lock audit log: %w", err)
+	}
+	defer func() { _ = unlock(f) }()
+
+	prev, err := lastHash(f)
+	if err != nil {
+		return err
+	}
+
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	e.Prev = prev
+	if e.Hash, err = computeHash(e); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return writeHead(path, f, e)
+}
+
+// writeHead advances the head record beside the log at path to e, which
+// was just appended to f. The events are counted afresh if the head did not
+// point at e's predecessor, e.g. for a log written before heads existed.
+func writeHead(path string, f *os.File, e Event) error {
+	h, err := readHead(path)
+	if err != nil {
+		return err
+	}
+	if h.Hash == e.Prev {
+		h.Count++
+	} else if h.Count, err = countEvents(f); err != nil {
+		return err
+	}
+	h.Hash = e.Hash
+	data, err := json.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit head: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(path+headSuffix, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write audit head: %w", err)
+	}
+	return nil
+}
+
+// countEvents returns the number of lines in f.
+func countEvents(f *os.File) (int, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	data := make([]byte, info.Size())
+	if _, err := f.ReadAt(data, 0); err != nil && err != io.EOF {
+		return 0, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return bytes.Count(data, []byte("\n")), nil
+}
+
+// readHead returns the head record beside the log at path; a missing
+// record is an empty log.
+func readHead(path string) (head, error) {
+	var h head
+	data, err := os.ReadFile(path + headSuffix)
+	if os.IsNotExist(err) {
+		return h, nil
+	}
+	if err != nil {
+		return h, fmt.Errorf("failed to read audit head: %w", err)
+	}
+	if err := json.Unmarshal(data, &h); err != nil {
+		return h, fmt.Errorf("audit head is corrupt: %w", err)
+	}
+	return h, nil
+}
+
+// lastHash returns the hash of the last event in f, or "" if f is empty.
+func lastHash(f *os.File) (string, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to read audit log: %w", err)
+	}
+	offset := max(info.Size()-tailSize, 0)
+	tail := make([]byte, info.Size()-offset)
+	if _, err := f.ReadAt(tail, offset); err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	lines := bytes.Split(bytes.TrimRight(tail, "\n"), []byte("\n"))
+	last := lines[len(lines)-1]
+	if len(last) == 0 {
+		return "", nil
+	}
+	var e Event
+	if err := json.Unmarshal(last, &e); err != nil {
+		return "", fmt.Errorf("audit log is corrupt: last line is not an event")
+	}
+	return e.Hash, nil
+}
+
+// Read returns every event in the log at path. A missing log has no events.
+func Read(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var events []Event
+	s := bufio.NewScanner(f)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; s.Scan(); line++ {
+		var e Event
+		if err := json.Unmarshal(s.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("audit log line %d: %w", line, err)
+		}
+		events = append(events, e)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return events, nil
+}
+
+// Verify checks the hash chain and reports the first event (1-based line)
+// that was modified, inserted or follows a deleted event.
+func Verify(events []Event) error {
+	prev := ""
+	for i, e := range events {
+		if e.Prev != prev {
+			return fmt.Errorf("chain broken at line %d: previous hash does not match", i+1)
+		}
+		want, err := computeHash(e)
+		if err != nil {
+			return err
+		}
+		if e.Hash != want {
+			return fmt.Errorf("chain broken at line %d: event was modified", i+1)
+		}
+		prev = e.Hash
+	}
+	return nil
+}
+
+// VerifyHead checks that events, read from the log at path, still reach
+// the event its head record points at, detecting events cut off the end.
+// Events past the head are tolerated: they are left by a crash between
+// writing an event and advancing the head.
+func VerifyHead(path string, events []Event) error {
+	h, err := readHead(path)
+	if err != nil {
+		return err
+	}
+	if len(events) < h.Count {
+		return fmt.Errorf("log ends at event %d, but %d were recorded", len(events), h.Count)
+	}
+	if h.Count > 0 && events[h.Count-1].Hash != h.Hash {
+		return fmt.Errorf("chain broken at line %d: event does not match the head record", h.Count)
+	}
+	return nil
+}
+
+// Caller returns the PID and, where the OS exposes it, the executable of
+// the process that started sekret.
+func Caller() (int, string) {
+	pid := os.Getppid()
+	exe, err := os.Readlink("/proc/" + strconv.Itoa(pid) + "/exe")
+	if err != nil {
+		return pid, ""
+	}
+	return pid, exe
+}
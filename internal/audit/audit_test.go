@@ -0,0 +1,143 @@
+package audit_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/eazyhozy/sekret/internal/audit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeEvents(t *testing.T, path string, ops ...string) {
+	t.Helper()
+	for _, op := range ops {
+		require.NoError(t, audit.Append(path, audit.Event{Op: op, Keys: []string{"OPENAI_API_KEY"}, Command: op, Profile: "default"}))
+	}
+}
+
+func TestAppend_ChainsEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeEvents(t, path, "add", "read", "remove")
+
+	events, err := audit.Read(path)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Empty(t, events[0].Prev)
+	assert.Equal(t, events[0].Hash, events[1].Prev)
+	assert.Equal(t, events[1].Hash, events[2].Prev)
+	assert.False(t, events[0].Time.IsZero())
+	require.NoError(t, audit.Verify(events))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestAppend_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, audit.Append(path, audit.Event{Op: "read", Command: "run", Profile: "default"}))
+		}()
+	}
+	wg.Wait()
+
+	events, err := audit.Read(path)
+	require.NoError(t, err)
+	assert.Len(t, events, 20)
+	assert.NoError(t, audit.Verify(events))
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeEvents(t, path, "add", "read", "read", "remove")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"modified", []string{lines[0], strings.Replace(lines[1], `"op":"read"`, `"op":"set"`, 1), lines[2], lines[3]}, "line 2: event was modified"},
+		{"deleted", []string{lines[0], lines[2], lines[3]}, "line 2: previous hash"},
+		{"truncated head", lines[1:], "line 1: previous hash"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := filepath.Join(t.TempDir(), "audit.log")
+			require.NoError(t, os.WriteFile(tampered, []byte(strings.Join(tt.lines, "\n")+"\n"), 0o600))
+
+			events, err := audit.Read(tampered)
+			require.NoError(t, err)
+			err = audit.Verify(events)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestRead_MissingLog(t *testing.T) {
+	events, err := audit.Read(filepath.Join(t.TempDir(), "audit.log"))
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestVerifyHead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeEvents(t, path, "add", "read", "read", "remove")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+	tests := []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"intact", lines, ""},
+		{"truncated tail", lines[:3], "log ends at event 3, but 4 were recorded"},
+		{"replaced tail", append(lines[:3:3], lines[2]), "line 4: event does not match the head record"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, os.WriteFile(path, []byte(strings.Join(tt.lines, "\n")+"\n"), 0o600))
+
+			events, err := audit.Read(path)
+			require.NoError(t, err)
+			err = audit.VerifyHead(path, events)
+			if tt.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestAppend_CountsEventsWithoutHead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	writeEvents(t, path, "add", "read")
+	require.NoError(t, os.Remove(path+".head"))
+
+	writeEvents(t, path, "remove")
+
+	events, err := audit.Read(path)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.NoError(t, audit.VerifyHead(path, events))
+	require.NoError(t, os.WriteFile(path, []byte(""), 0o600))
+	assert.Error(t, audit.VerifyHead(path, nil))
+}
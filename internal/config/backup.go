@@ -0,0 +1,127 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/eazyhozy/sekret/internal/fsutil"
+)
+
+const backupDir = "backups"
+
+// backupCount is how many previous configs are kept.
+const backupCount = 10
+
+// backupTimeFormat names backups so that they sort chronologically.
+const backupTimeFormat = "20060102T150405.000000000Z"
+
+// Backup is a copy of the config file taken before it was overwritten.
+type Backup struct {
+	Path string
+	Time time.Time
+}
+
+// backupCurrent copies the config file at path into the backup directory
+// before it is replaced with next, keeping the newest backupCount copies.
+// Nothing is backed up if the file is missing or unchanged.
+func backupCurrent(path string, next []byte) error {
+	current, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+	if bytes.Equal(current, next) {
+		return nil
+	}
+
+	dir := filepath.Join(filepath.Dir(path), backupDir)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create backup directory: %w", err)
+	}
+	name := "config-" + time.Now().UTC().Format(backupTimeFormat) + ".json"
+	if err := fsutil.WriteFileAtomic(filepath.Join(dir, name), current, 0o600); err != nil {
+		return fmt.Errorf("failed to back up config: %w", err)
+	}
+
+	backups, err := listBackups(dir)
+	if err != nil {
+		return err
+	}
+	for _, b := range backups[min(backupCount, len(backups)):] {
+		_ = os.Remove(b.Path)
+	}
+	return nil
+}
+
+// listBackups returns the backups in dir, newest first.
+func listBackups(dir string) ([]Backup, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	var backups []Backup
+	for _, e := range entries {
+		stamp, ok := strings.CutPrefix(e.Name(), "config-")
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(backupTimeFormat, strings.TrimSuffix(stamp, ".json"))
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{Path: filepath.Join(dir, e.Name()), Time: t})
+	}
+	slices.SortFunc(backups, func(a, b Backup) int { return b.Time.Compare(a.Time) })
+	return backups, nil
+}
+
+// Backups returns the saved backups of the config file, newest first.
+func Backups() ([]Backup, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return listBackups(filepath.Join(dir, backupDir))
+}
+
+// LoadBackup parses a backup without restoring it.
+func LoadBackup(b Backup) (*Config, error) {
+	data, err := os.ReadFile(b.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("backup %s is corrupt: %w", filepath.Base(b.Path), err)
+	}
+	return &cfg, nil
+}
+
+// RestoreBackup replaces the config file with a backup. The config being
+// replaced is itself backed up first.
+func RestoreBackup(b Backup) error {
+	if _, err := LoadBackup(b); err != nil {
+		return err
+	}
+	data, err := os.ReadFile(b.Path)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+	path, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+	return writeConfig(path, data)
+}
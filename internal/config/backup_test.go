@@ -0,0 +1,64 @@
+package config_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSave_KeepsBackups(t *testing.T) {
+	config.SetPath(t.TempDir())
+	t.Cleanup(func() { config.SetPath("") })
+
+	cfg := &config.Config{Version: 1, Keys: []config.KeyEntry{}}
+	require.NoError(t, config.Save(cfg))
+	backups, err := config.Backups()
+	require.NoError(t, err)
+	assert.Empty(t, backups, "the first save has nothing to back up")
+
+	require.NoError(t, config.Save(cfg))
+	backups, err = config.Backups()
+	require.NoError(t, err)
+	assert.Empty(t, backups, "unchanged saves are not backed up")
+
+	for i := range 12 {
+		require.NoError(t, cfg.AddKey("", fmt.Sprintf("KEY_%d", i)))
+		require.NoError(t, config.Save(cfg))
+	}
+	backups, err = config.Backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 10)
+
+	newest, err := config.LoadBackup(backups[0])
+	require.NoError(t, err)
+	assert.Len(t, newest.Keys, 11, "the newest backup is the config before the last save")
+	assert.True(t, backups[0].Time.After(backups[9].Time))
+}
+
+func TestRestoreBackup(t *testing.T) {
+	config.SetPath(t.TempDir())
+	t.Cleanup(func() { config.SetPath("") })
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	require.NoError(t, cfg.AddKey("", "FIRST_KEY"))
+	require.NoError(t, config.Save(cfg))
+	require.NoError(t, cfg.AddKey("", "SECOND_KEY"))
+	require.NoError(t, config.Save(cfg))
+
+	backups, err := config.Backups()
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	require.NoError(t, config.RestoreBackup(backups[0]))
+
+	restored, err := config.Load()
+	require.NoError(t, err)
+	assert.Len(t, restored.Keys, 1)
+
+	backups, err = config.Backups()
+	require.NoError(t, err)
+	assert.Len(t, backups, 2, "the replaced config is backed up")
+}
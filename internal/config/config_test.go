@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/eazyhozy/sekret/internal/config"
 	"github.com/stretchr/testify/assert"
@@ -23,7 +24,7 @@ func TestLoad_NoFile(t *testing.T) {
 
 	cfg, err := config.Load()
 	require.NoError(t, err)
-	assert.Equal(t, 1, cfg.Version)
+	assert.Equal(t, 2, cfg.Version)
 	assert.Empty(t, cfg.Keys)
 }
 
@@ -41,6 +42,29 @@ func TestSaveAndLoad(t *testing.T) {
 	assert.Equal(t, "OPENAI_API_KEY", loaded.Keys[0].EnvVar)
 }
 
+func TestSave_RejectsConcurrentChange(t *testing.T) {
+	setupTestDir(t)
+
+	stale, err := config.Load()
+	require.NoError(t, err)
+	other, err := config.Load()
+	require.NoError(t, err)
+
+	require.NoError(t, other.AddKey("", "OPENAI_API_KEY"))
+	require.NoError(t, config.Save(other))
+	require.NoError(t, other.AddKey("", "GITHUB_TOKEN"))
+	require.NoError(t, config.Save(other), "a config may be saved again")
+
+	require.NoError(t, stale.AddKey("", "ANTHROPIC_API_KEY"))
+	err = config.Save(stale)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "changed by another sekret process")
+
+	loaded, err := config.Load()
+	require.NoError(t, err)
+	assert.Len(t, loaded.Keys, 2)
+}
+
 func TestAddKey_Duplicate(t *testing.T) {
 	setupTestDir(t)
 
@@ -60,6 +84,24 @@ func TestAddKey_DuplicateEnvVar(t *testing.T) {
 	assert.Contains(t, err.Error(), "already used by")
 }
 
+func TestAddEntry_Derived(t *testing.T) {
+	setupTestDir(t)
+
+	cfg := &config.Config{Version: 1, Keys: []config.KeyEntry{}}
+	require.NoError(t, cfg.AddEntry(config.KeyEntry{EnvVar: "DATABASE_URL", Kind: config.KindDerived, Template: "postgres://${DB_PASSWORD}@db"}))
+	require.NoError(t, config.Save(cfg))
+
+	loaded, err := config.Load()
+	require.NoError(t, err)
+	entry := loaded.FindKeyByEnvVar("DATABASE_URL")
+	require.NotNil(t, entry)
+	assert.True(t, entry.IsDerived())
+	assert.False(t, entry.AddedAt.IsZero())
+
+	err = loaded.AddEntry(config.KeyEntry{EnvVar: "DATABASE_URL"})
+	assert.Error(t, err)
+}
+
 func TestRemoveKey_ByEnvVar(t *testing.T) {
 	setupTestDir(t)
 
@@ -107,3 +149,90 @@ func TestSave_CreatesDirectory(t *testing.T) {
 	_, err := os.Stat(filepath.Join(nested, "config.json"))
 	assert.NoError(t, err)
 }
+
+func TestRegistries(t *testing.T) {
+	setupTestDir(t)
+
+	cfg := &config.Config{Version: 1, Keys: []config.KeyEntry{}}
+	cfg.SetRegistry(config.RegistryLogin{ServerURL: "https://ghcr.io", Username: "old"})
+	cfg.SetRegistry(config.RegistryLogin{ServerURL: "https://ghcr.io", Username: "octocat"})
+	require.NoError(t, config.Save(cfg))
+
+	loaded, err := config.Load()
+	require.NoError(t, err)
+	require.Len(t, loaded.Registries, 1)
+	login := loaded.FindRegistry("https://ghcr.io")
+	require.NotNil(t, login)
+	assert.Equal(t, "octocat", login.Username)
+	assert.Equal(t, "docker:https://ghcr.io", login.KeychainKey())
+
+	require.NoError(t, loaded.RemoveRegistry("https://ghcr.io"))
+	assert.Error(t, loaded.RemoveRegistry("https://ghcr.io"))
+}
+
+func TestMCPAllowed(t *testing.T) {
+	cfg := &config.Config{Version: 1, Keys: []config.KeyEntry{}}
+	cfg.AddMCPRule(config.MCPRule{Command: "npm test", Keys: []string{"NPM_TOKEN"}})
+	cfg.AddMCPRule(config.MCPRule{Command: "python *", Keys: []string{"OPENAI_*", "ANTHROPIC_API_KEY"}})
+
+	tests := []struct {
+		command string
+		keys    []string
+		want    bool
+	}{
+		{"npm test", []string{"NPM_TOKEN"}, true},
+		{"npm test --watch", []string{"NPM_TOKEN"}, false},
+		{"npm test", []string{"GITHUB_TOKEN"}, false},
+		{"python scripts/eval.py", []string{"OPENAI_API_KEY", "ANTHROPIC_API_KEY"}, true},
+		{"python scripts/eval.py", []string{"OPENAI_API_KEY", "GITHUB_TOKEN"}, false},
+		{"python3 x.py", []string{"OPENAI_API_KEY"}, false},
+		{"python x.py", nil, true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, cfg.MCPAllowed(tt.command, tt.keys), "%s %v", tt.command, tt.keys)
+	}
+
+	cfg.AddMCPRule(config.MCPRule{Command: "npm test", Keys: []string{"GITHUB_TOKEN"}})
+	assert.Len(t, cfg.MCPAllow, 2, "same command pattern replaces the rule")
+	assert.True(t, cfg.MCPAllowed("npm test", []string{"GITHUB_TOKEN"}))
+}
+
+func TestPolicies(t *testing.T) {
+	cfg := &config.Config{Version: 1, Keys: []config.KeyEntry{}}
+	cfg.SetPolicy(config.Policy{Keys: "AWS_*", Commands: []string{"aws", "terraform"}})
+	cfg.SetPolicy(config.Policy{Keys: "OPENAI_API_KEY", Commands: []string{"python*", "/opt/tools/*"}})
+
+	aws := cfg.PoliciesFor("AWS_SECRET_ACCESS_KEY")
+	require.Len(t, aws, 1)
+	assert.True(t, aws[0].AllowsCommand("terraform", "/usr/bin/terraform"))
+	assert.False(t, aws[0].AllowsCommand("curl", "/usr/bin/curl"))
+
+	openai := cfg.PoliciesFor("OPENAI_API_KEY")
+	require.Len(t, openai, 1)
+	assert.True(t, openai[0].AllowsCommand("python3.12", "/usr/bin/python3.12"))
+	assert.True(t, openai[0].AllowsCommand("agent", "/opt/tools/agent"))
+	assert.False(t, openai[0].AllowsCommand("agent", "/home/me/agent"))
+
+	assert.Empty(t, cfg.PoliciesFor("GITHUB_TOKEN"))
+
+	cfg.SetPolicy(config.Policy{Keys: "AWS_*", Commands: []string{"aws"}})
+	assert.Len(t, cfg.Policies, 2, "same key glob replaces the policy")
+	require.NoError(t, cfg.RemovePolicy("AWS_*"))
+	assert.Error(t, cfg.RemovePolicy("AWS_*"))
+}
+
+func TestHistory(t *testing.T) {
+	cfg := &config.Config{Version: 1}
+	assert.Equal(t, config.DefaultHistoryLimit, cfg.MaxHistory())
+	cfg.HistoryLimit = -1
+	assert.Equal(t, 0, cfg.MaxHistory())
+
+	added := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	entry := config.KeyEntry{Name: "openai", EnvVar: "OPENAI_API_KEY", AddedAt: added}
+	assert.Equal(t, added, entry.SetAt())
+	assert.Equal(t, "history:openai:3", entry.HistoryKey(3))
+
+	entry.History = []config.Version{{Seq: 1}, {Seq: 2}}
+	require.NotNil(t, entry.FindVersion(2))
+	assert.Nil(t, entry.FindVersion(3))
+}
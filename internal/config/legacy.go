@@ -0,0 +1,83 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// RekeyLegacy moves the keychain items of a legacy entry (one with Name
+// set) to the names they have once Name is cleared, verifying the copies.
+// trashed reports whether the entry is in the trash. It returns a function
+// that deletes the old items, run once the migrated config is written.
+//
+// The keychain belongs to the command layer, which sets this hook. While it
+// is nil, the version 2 migration leaves legacy entries as they are.
+var RekeyLegacy func(entry KeyEntry, trashed bool) (cleanup func(), err error)
+
+// legacyCleanups holds the cleanups of the migration in progress.
+var legacyCleanups []func()
+
+func init() {
+	RegisterMigration(Migration{
+		From:        1,
+		Description: "re-key legacy entries by env var",
+		Apply:       rekeyLegacyEntries,
+		Finish: func() {
+			for _, cleanup := range legacyCleanups {
+				cleanup()
+			}
+			legacyCleanups = nil
+		},
+	})
+}
+
+// rekeyLegacyEntries clears the name of every legacy key and trashed key
+// whose keychain items RekeyLegacy moved. Entries it fails on stay legacy.
+func rekeyLegacyEntries(raw map[string]any) error {
+	legacyCleanups = nil
+	if RekeyLegacy == nil {
+		return nil
+	}
+
+	keys, _ := raw["keys"].([]any)
+	for _, k := range keys {
+		if m, ok := k.(map[string]any); ok {
+			if err := rekeyLegacyEntry(m, false); err != nil {
+				return err
+			}
+		}
+	}
+	trash, _ := raw["trash"].([]any)
+	for _, t := range trash {
+		if tm, ok := t.(map[string]any); ok {
+			if m, ok := tm["entry"].(map[string]any); ok {
+				if err := rekeyLegacyEntry(m, true); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func rekeyLegacyEntry(m map[string]any, trashed bool) error {
+	if name, _ := m["name"].(string); name == "" {
+		return nil
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	var entry KeyEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return fmt.Errorf("invalid key entry: %w", err)
+	}
+
+	cleanup, err := RekeyLegacy(entry, trashed)
+	if err != nil {
+		return nil // reported by the hook; the entry keeps working by name
+	}
+	delete(m, "name")
+	legacyCleanups = append(legacyCleanups, cleanup)
+	return nil
+}
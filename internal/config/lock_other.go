@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly || windows)
+
+package config
+
+import "os"
+
+// tryLock is a no-op where advisory locks are unavailable.
+func tryLock(*os.File) (bool, error) {
+	return true, nil
+}
+
+func unlock(*os.File) error {
+	return nil
+}
@@ -0,0 +1,39 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eazyhozy/sekret/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock_Exclusive(t *testing.T) {
+	config.SetPath(t.TempDir())
+	t.Cleanup(func() { config.SetPath("") })
+
+	unlock, err := config.Lock()
+	require.NoError(t, err)
+
+	acquired := make(chan func())
+	go func() {
+		second, err := config.Lock()
+		assert.NoError(t, err)
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second lock acquired while the first is held")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case second := <-acquired:
+		second()
+	case <-time.After(2 * time.Second):
+		t.Fatal("second lock not acquired after release")
+	}
+}
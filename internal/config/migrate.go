@@ -0,0 +1,109 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Migration upgrades a config file from schema version From to From+1. It
+// works on the decoded JSON so that old schemas need no Go types.
+type Migration struct {
+	From        int
+	Description string
+	Apply       func(raw map[string]any) error
+
+	// Finish, if set, runs once the upgraded config has been written, e.g.
+	// to delete data only the old config referenced.
+	Finish func()
+}
+
+// migrations holds the registered steps by the version they upgrade from.
+var migrations = map[int]Migration{}
+
+// RegisterMigration adds a step to the migration pipeline. Steps are
+// registered at init time; registering two steps from the same version
+// panics.
+func RegisterMigration(m Migration) {
+	if _, ok := migrations[m.From]; ok {
+		panic(fmt.Sprintf("config: migration from version %d registered twice", m.From))
+	}
+	migrations[m.From] = m
+}
+
+// Migrate upgrades the config file on disk to the current schema version,
+// backing up the original first. It returns the version it upgraded from,
+// or 0 if there was nothing to upgrade.
+func Migrate() (int, error) {
+	path, err := getConfigPath()
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read config file: %w", err)
+	}
+	_, from, err := upgrade(path, data)
+	return from, err
+}
+
+// upgrade migrates the config file content data, read from path, to the
+// current version and writes the result back. It returns the current
+// content and the version upgraded from (0 if already current).
+func upgrade(path string, data []byte) ([]byte, int, error) {
+	var head struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	from := max(head.Version, 1) // files without a version predate versioning
+
+	if from > currentVersion {
+		return nil, 0, fmt.Errorf("config file %s was written by a newer sekret (schema version %d, this sekret supports %d): upgrade sekret, or roll back with 'sekret config restore'",
+			path, from, currentVersion)
+	}
+	if from == currentVersion {
+		return data, 0, nil
+	}
+
+	migrated, err := runMigrations(data, from, currentVersion, migrations)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := writeConfig(path, migrated); err != nil {
+		return nil, 0, err
+	}
+	for v := from; v < currentVersion; v++ {
+		if m := migrations[v]; m.Finish != nil {
+			m.Finish()
+		}
+	}
+	return migrated, from, nil
+}
+
+// runMigrations applies steps to data, one version at a time, from from to to.
+func runMigrations(data []byte, from, to int, steps map[int]Migration) ([]byte, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	for v := from; v < to; v++ {
+		m, ok := steps[v]
+		if !ok {
+			return nil, fmt.Errorf("no migration from config version %d", v)
+		}
+		if err := m.Apply(raw); err != nil {
+			return nil, fmt.Errorf("config migration from version %d (%s) failed: %w", v, m.Description, err)
+		}
+		raw["version"] = v + 1
+	}
+	data, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize config: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMigrations_StepByStep(t *testing.T) {
+	var applied []int
+	steps := map[int]Migration{
+		1: {From: 1, Description: "add profiles", Apply: func(raw map[string]any) error {
+			applied = append(applied, 1)
+			raw["profiles"] = []any{"default"}
+			return nil
+		}},
+		2: {From: 2, Description: "rename keys", Apply: func(raw map[string]any) error {
+			applied = append(applied, 2)
+			raw["entries"] = raw["keys"]
+			delete(raw, "keys")
+			return nil
+		}},
+	}
+
+	out, err := runMigrations([]byte(`{"version":1,"keys":[]}`), 1, 3, steps)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, applied)
+	assert.JSONEq(t, `{"version":3,"profiles":["default"],"entries":[]}`, string(out))
+}
+
+func TestRunMigrations_Errors(t *testing.T) {
+	_, err := runMigrations([]byte(`{"version":1}`), 1, 2, map[int]Migration{})
+	assert.ErrorContains(t, err, "no migration from config version 1")
+
+	steps := map[int]Migration{1: {From: 1, Description: "broken", Apply: func(map[string]any) error {
+		return errors.New("boom")
+	}}}
+	_, err = runMigrations([]byte(`{"version":1}`), 1, 2, steps)
+	assert.ErrorContains(t, err, "(broken) failed: boom")
+}
+
+func TestLoad_RefusesNewerConfig(t *testing.T) {
+	dir := t.TempDir()
+	SetPath(dir)
+	t.Cleanup(func() { SetPath("") })
+	require.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte(`{"version":99,"keys":[]}`), 0o600))
+
+	_, err := Load()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "written by a newer sekret")
+
+	_, err = Migrate()
+	assert.Error(t, err)
+}
+
+func TestMigrate_CurrentIsUntouched(t *testing.T) {
+	dir := t.TempDir()
+	SetPath(dir)
+	t.Cleanup(func() { SetPath("") })
+	require.NoError(t, Save(&Config{Version: currentVersion, Keys: []KeyEntry{}}))
+
+	from, err := Migrate()
+	require.NoError(t, err)
+	assert.Zero(t, from)
+
+	backups, err := Backups()
+	require.NoError(t, err)
+	assert.Empty(t, backups)
+}
+
+func TestMigrate_RekeysLegacyEntries(t *testing.T) {
+	dir := t.TempDir()
+	SetPath(dir)
+	t.Cleanup(func() { SetPath("") })
+	require.NoError(t, os.WriteFile(filepath.Join(dir, configFile), []byte(`{"version":1,"keys":[
+		{"name":"openai","env_var":"OPENAI_API_KEY"},
+		{"name":"stuck","env_var":"STUCK_KEY"},
+		{"env_var":"NEW_KEY"}
+	]}`), 0o600))
+
+	var rekeyed []string
+	cleaned := 0
+	RekeyLegacy = func(entry KeyEntry, trashed bool) (func(), error) {
+		if entry.Name == "stuck" {
+			return nil, errors.New("conflict")
+		}
+		rekeyed = append(rekeyed, entry.Name)
+		return func() { cleaned++ }, nil
+	}
+	t.Cleanup(func() { RekeyLegacy = nil })
+
+	from, err := Migrate()
+	require.NoError(t, err)
+	assert.Equal(t, 1, from)
+	assert.Equal(t, []string{"openai"}, rekeyed)
+	assert.Equal(t, 1, cleaned)
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, currentVersion, cfg.Version)
+	assert.Empty(t, cfg.FindKeyByEnvVar("OPENAI_API_KEY").Name)
+	assert.Equal(t, "stuck", cfg.FindKeyByEnvVar("STUCK_KEY").Name, "failed entries stay legacy")
+}
@@ -0,0 +1,210 @@
+package derive
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// namePattern matches a referenced env var name.
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// filters maps filter names to their per-value transform.
+// join is handled separately because it combines values.
+var filters = map[string]func(string) (string, error){
+	"base64": func(s string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(s)), nil
+	},
+	"base64decode": func(s string) (string, error) {
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return "", fmt.Errorf("base64decode: %w", err)
+		}
+		return string(b), nil
+	},
+	"urlescape": func(s string) (string, error) {
+		// QueryEscape encodes spaces as '+', which is only valid in queries.
+		return strings.ReplaceAll(url.QueryEscape(s), "+", "%20"), nil
+	},
+}
+
+// expr is a parsed ${...} expression.
+type expr struct {
+	names   []string
+	filters []filter
+}
+
+type filter struct {
+	name string
+	arg  string
+}
+
+// segment is either literal text or an expression.
+type segment struct {
+	text string
+	expr *expr
+}
+
+// Expand evaluates a template, replacing each ${...} expression with the
+// value produced by lookup and the expression's filters.
+//
+// Expressions reference one or more env vars and apply filters left to right:
+//
+//	${DB_PASSWORD}
+//	${DB_PASSWORD|urlescape}
+//	${USER,PASSWORD|join::|base64}
+//
+// Filters before join apply to each value; join:SEP concatenates them.
+// Use $$ for a literal $.
+func Expand(tmpl string, lookup func(name string) (string, error)) (string, error) {
+	segments, err := parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	for _, seg := range segments {
+		if seg.expr == nil {
+			b.WriteString(seg.text)
+			continue
+		}
+		val, err := seg.expr.eval(lookup)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(val)
+	}
+	return b.String(), nil
+}
+
+// References returns the env var names referenced by a template, in order
+// of first appearance. It also validates the template syntax.
+func References(tmpl string) ([]string, error) {
+	segments, err := parse(tmpl)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var names []string
+	for _, seg := range segments {
+		if seg.expr == nil {
+			continue
+		}
+		for _, n := range seg.expr.names {
+			if !seen[n] {
+				seen[n] = true
+				names = append(names, n)
+			}
+		}
+	}
+	return names, nil
+}
+
+func (e *expr) eval(lookup func(string) (string, error)) (string, error) {
+	values := make([]string, len(e.names))
+	for i, n := range e.names {
+		v, err := lookup(n)
+		if err != nil {
+			return "", err
+		}
+		values[i] = v
+	}
+
+	for _, f := range e.filters {
+		if f.name == "join" {
+			values = []string{strings.Join(values, f.arg)}
+			continue
+		}
+		fn := filters[f.name]
+		for i, v := range values {
+			out, err := fn(v)
+			if err != nil {
+				return "", err
+			}
+			values[i] = out
+		}
+	}
+	return values[0], nil
+}
+
+// parse splits a template into literal text and expressions.
+func parse(tmpl string) ([]segment, error) {
+	var segments []segment
+	var text strings.Builder
+
+	for i := 0; i < len(tmpl); i++ {
+		if tmpl[i] != '$' || i+1 >= len(tmpl) {
+			text.WriteByte(tmpl[i])
+			continue
+		}
+		switch tmpl[i+1] {
+		case '$':
+			text.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(tmpl[i+2:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated ${ at offset %d", i)
+			}
+			e, err := parseExpr(tmpl[i+2 : i+2+end])
+			if err != nil {
+				return nil, err
+			}
+			if text.Len() > 0 {
+				segments = append(segments, segment{text: text.String()})
+				text.Reset()
+			}
+			segments = append(segments, segment{expr: e})
+			i += 2 + end
+		default:
+			text.WriteByte('$')
+		}
+	}
+
+	if text.Len() > 0 {
+		segments = append(segments, segment{text: text.String()})
+	}
+	return segments, nil
+}
+
+// parseExpr parses the inside of ${...}.
+func parseExpr(s string) (*expr, error) {
+	parts := strings.Split(s, "|")
+
+	e := &expr{}
+	for _, n := range strings.Split(parts[0], ",") {
+		n = strings.TrimSpace(n)
+		if !namePattern.MatchString(n) {
+			return nil, fmt.Errorf("invalid variable name %q in ${%s}", n, s)
+		}
+		e.names = append(e.names, n)
+	}
+
+	count := len(e.names)
+	for _, p := range parts[1:] {
+		name, arg, hasArg := strings.Cut(p, ":")
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "join":
+			if !hasArg {
+				return nil, fmt.Errorf("join requires a separator (join:SEP) in ${%s}", s)
+			}
+			count = 1
+		case filters[name] != nil:
+			if hasArg {
+				return nil, fmt.Errorf("filter %q takes no argument in ${%s}", name, s)
+			}
+		default:
+			return nil, fmt.Errorf("unknown filter %q in ${%s}", name, s)
+		}
+		e.filters = append(e.filters, filter{name: name, arg: arg})
+	}
+
+	if count != 1 {
+		return nil, fmt.Errorf("multiple variables require a join filter in ${%s}", s)
+	}
+	return e, nil
+}
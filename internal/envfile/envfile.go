@@ -0,0 +1,89 @@
+package envfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Var is a single KEY=VALUE assignment read from an env file.
+type Var struct {
+	Key   string
+	Value string
+	Line  int
+}
+
+// assignPattern matches `KEY=VALUE` lines, with an optional leading `export`.
+var assignPattern = regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)\s*=(.*)$`)
+
+// Read parses the env file at path.
+func Read(path string) ([]Var, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	vars, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// Parse reads KEY=VALUE assignments in dotenv format.
+// Blank lines and lines starting with # are ignored. Values may be
+// single-quoted (taken literally), double-quoted, or bare; bare values
+// end at an unquoted " #" comment.
+func Parse(r io.Reader) ([]Var, error) {
+	var vars []Var
+	lineNum := 0
+	s := bufio.NewScanner(r)
+
+	for s.Scan() {
+		lineNum++
+		line := s.Text()
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		matches := assignPattern.FindStringSubmatch(line)
+		if matches == nil {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNum)
+		}
+
+		vars = append(vars, Var{
+			Key:   matches[1],
+			Value: parseValue(matches[2]),
+			Line:  lineNum,
+		})
+	}
+
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+// parseValue strips quotes and trailing comments from a raw value.
+func parseValue(raw string) string {
+	s := strings.TrimSpace(raw)
+	if s != "" && (s[0] == '\'' || s[0] == '"') {
+		if end := strings.LastIndexByte(s, s[0]); end > 0 {
+			inner := s[1:end]
+			if s[0] == '"' {
+				inner = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(inner)
+			}
+			return inner
+		}
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s
+}
@@ -0,0 +1,58 @@
+package envfile_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eazyhozy/sekret/internal/envfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Formats(t *testing.T) {
+	input := `# comment
+OPENAI_API_KEY=sekret://OPENAI_API_KEY
+
+export BASE_URL="https://api.example.com"
+SINGLE='literal $value'
+BARE=value # trailing comment
+QUOTED="quoted" # trailing comment
+EMPTY=
+`
+	vars, err := envfile.Parse(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, vars, 6)
+
+	got := map[string]string{}
+	for _, v := range vars {
+		got[v.Key] = v.Value
+	}
+	assert.Equal(t, "sekret://OPENAI_API_KEY", got["OPENAI_API_KEY"])
+	assert.Equal(t, "https://api.example.com", got["BASE_URL"])
+	assert.Equal(t, "literal $value", got["SINGLE"])
+	assert.Equal(t, "value", got["BARE"])
+	assert.Equal(t, "quoted", got["QUOTED"])
+	assert.Equal(t, "", got["EMPTY"])
+	assert.Equal(t, 2, vars[0].Line)
+}
+
+func TestParse_DoubleQuotedEscapes(t *testing.T) {
+	vars, err := envfile.Parse(strings.NewReader(`MULTI="line1\nline2 \"q\""`))
+	require.NoError(t, err)
+	require.Len(t, vars, 1)
+	assert.Equal(t, "line1\nline2 \"q\"", vars[0].Value)
+}
+
+func TestParse_InvalidLine(t *testing.T) {
+	_, err := envfile.Parse(strings.NewReader("GOOD=1\nnot an assignment\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+}
+
+func TestRead_MissingFile(t *testing.T) {
+	_, err := envfile.Read(filepath.Join(t.TempDir(), "missing.env"))
+	require.Error(t, err)
+	assert.True(t, os.IsNotExist(err))
+}
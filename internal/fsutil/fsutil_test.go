@@ -0,0 +1,56 @@
+package fsutil_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eazyhozy/sekret/internal/fsutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileAtomic_ReplacesContent(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0o644))
+
+	require.NoError(t, fsutil.WriteFileAtomic(path, []byte("new"), 0o600))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temp file should not be left behind")
+}
+
+func TestWriteFileAtomic_MissingDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "out.txt")
+	assert.Error(t, fsutil.WriteFileAtomic(path, []byte("data"), 0o600))
+}
+
+func TestResolveSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", ".zshrc")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0o755))
+	require.NoError(t, os.WriteFile(target, []byte("rc"), 0o644))
+	link := filepath.Join(dir, ".zshrc")
+	require.NoError(t, os.Symlink(target, link))
+
+	resolved, err := fsutil.ResolveSymlinks(link)
+	require.NoError(t, err)
+	want, err := filepath.EvalSymlinks(target)
+	require.NoError(t, err)
+	assert.Equal(t, want, resolved)
+
+	missing := filepath.Join(dir, ".bashrc")
+	resolved, err = fsutil.ResolveSymlinks(missing)
+	require.NoError(t, err)
+	assert.Equal(t, missing, resolved)
+}
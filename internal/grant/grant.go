@@ -0,0 +1,90 @@
+package grant
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eazyhozy/sekret/internal/fsutil"
+)
+
+const grantsFile = "grants.json"
+
+// Cache remembers which sensitive keys were released to a terminal session
+// and until when. It is a convenience, not a security boundary: anyone who
+// can write the file can already read the config and run sekret.
+type Cache struct {
+	path    string
+	session string
+	now     func() time.Time
+	expires map[string]time.Time
+}
+
+// Open loads the grant cache stored in dir for the current session.
+// A missing or unreadable file yields an empty cache.
+func Open(dir string) *Cache {
+	c := &Cache{
+		path:    filepath.Join(dir, grantsFile),
+		session: strconv.Itoa(SessionID()),
+		now:     time.Now,
+		expires: map[string]time.Time{},
+	}
+	if data, err := os.ReadFile(c.path); err == nil {
+		_ = json.Unmarshal(data, &c.expires)
+	}
+	return c
+}
+
+// SetClock overrides the time source (for testing).
+func (c *Cache) SetClock(now func() time.Time) {
+	c.now = now
+}
+
+// key scopes a grant to the current session.
+func (c *Cache) key(name string) string {
+	return c.session + ":" + name
+}
+
+// Valid reports whether name was granted in this session and has not expired.
+func (c *Cache) Valid(name string) bool {
+	exp, ok := c.expires[c.key(name)]
+	return ok && c.now().Before(exp)
+}
+
+// Grant records that name is released for ttl and saves the cache,
+// dropping expired grants. A zero ttl records nothing.
+func (c *Cache) Grant(name string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	c.expires[c.key(name)] = c.now().Add(ttl)
+	return c.save()
+}
+
+// Revoke removes every grant of the current session.
+func (c *Cache) Revoke() error {
+	prefix := c.session + ":"
+	maps.DeleteFunc(c.expires, func(k string, _ time.Time) bool {
+		return strings.HasPrefix(k, prefix)
+	})
+	return c.save()
+}
+
+func (c *Cache) save() error {
+	now := c.now()
+	maps.DeleteFunc(c.expires, func(_ string, exp time.Time) bool { return !now.Before(exp) })
+
+	data, err := json.Marshal(c.expires)
+	if err != nil {
+		return fmt.Errorf("failed to encode grants: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create grants directory: %w", err)
+	}
+	return fsutil.WriteFileAtomic(c.path, data, 0o600)
+}
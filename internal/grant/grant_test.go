@@ -0,0 +1,53 @@
+package grant_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eazyhozy/sekret/internal/grant"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache_GrantAndExpire(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	c := grant.Open(dir)
+	c.SetClock(func() time.Time { return now })
+	assert.False(t, c.Valid("DEPLOY_TOKEN"))
+	require.NoError(t, c.Grant("DEPLOY_TOKEN", 10*time.Minute))
+
+	// A fresh Open sees the persisted grant.
+	reopened := grant.Open(dir)
+	reopened.SetClock(func() time.Time { return now.Add(9 * time.Minute) })
+	assert.True(t, reopened.Valid("DEPLOY_TOKEN"))
+	assert.False(t, reopened.Valid("OTHER_TOKEN"))
+
+	reopened.SetClock(func() time.Time { return now.Add(10 * time.Minute) })
+	assert.False(t, reopened.Valid("DEPLOY_TOKEN"))
+
+	info, err := os.Stat(filepath.Join(dir, "grants.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestCache_ZeroTTLRecordsNothing(t *testing.T) {
+	dir := t.TempDir()
+	c := grant.Open(dir)
+	require.NoError(t, c.Grant("DEPLOY_TOKEN", 0))
+	assert.False(t, c.Valid("DEPLOY_TOKEN"))
+	_, err := os.Stat(filepath.Join(dir, "grants.json"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCache_Revoke(t *testing.T) {
+	dir := t.TempDir()
+	c := grant.Open(dir)
+	require.NoError(t, c.Grant("DEPLOY_TOKEN", time.Hour))
+	require.NoError(t, c.Revoke())
+
+	assert.False(t, grant.Open(dir).Valid("DEPLOY_TOKEN"))
+}
@@ -0,0 +1,11 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package grant
+
+import "os"
+
+// SessionID identifies the terminal session. Without POSIX sessions the
+// parent process (usually the shell) stands in for it.
+func SessionID() int {
+	return os.Getppid()
+}
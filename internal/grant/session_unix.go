@@ -0,0 +1,19 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package grant
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// SessionID identifies the terminal session, so a grant made in one
+// terminal does not unlock keys in another.
+func SessionID() int {
+	sid, err := unix.Getsid(0)
+	if err != nil {
+		return os.Getppid()
+	}
+	return sid
+}
@@ -0,0 +1,207 @@
+package journal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/eazyhozy/sekret/internal/fsutil"
+	"github.com/eazyhozy/sekret/internal/keychain"
+)
+
+// FileName is the journal's name within the config directory.
+const FileName = "journal.json"
+
+// backupPrefix names the keychain items holding values a transaction
+// overwrote or deleted. Values never go into the journal file itself.
+const backupPrefix = "journal:"
+
+const (
+	statePending   = "pending"
+	stateCommitted = "committed"
+)
+
+// record is the journal file content.
+type record struct {
+	Op    string `json:"op"`
+	State string `json:"state"`
+
+	// Config is the config file before the operation, or nil if there was none.
+	Config    []byte `json:"config"`
+	HadConfig bool   `json:"had_config"`
+
+	// Items lists the keychain items written, in order.
+	Items []item `json:"items"`
+}
+
+type item struct {
+	Name    string `json:"name"`
+	Existed bool   `json:"existed"`
+}
+
+// Tx is a journaled operation over the keychain and the config file. It
+// implements keychain.Store: every write made through it is recorded first,
+// so that an operation interrupted between keychain and config writes can
+// be rolled back, now or by Recover on the next run.
+type Tx struct {
+	store      keychain.Store
+	path       string
+	configPath string
+	rec        record
+}
+
+// Path returns the journal path for the config file at configPath.
+func Path(configPath string) string {
+	return filepath.Join(filepath.Dir(configPath), FileName)
+}
+
+// Begin starts a transaction named op. It fails if an earlier transaction
+// still needs recovery.
+func Begin(store keychain.Store, configPath, op string) (*Tx, error) {
+	tx := &Tx{store: store, path: Path(configPath), configPath: configPath}
+	if _, err := os.Stat(tx.path); err == nil {
+		return nil, fmt.Errorf("an interrupted operation has not been recovered (%s exists)", tx.path)
+	}
+
+	tx.rec = record{Op: op, State: statePending}
+	data, err := os.ReadFile(configPath)
+	switch {
+	case err == nil:
+		tx.rec.Config, tx.rec.HadConfig = data, true
+	case !errors.Is(err, os.ErrNotExist):
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(tx.path), 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	if err := tx.save(); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+func (tx *Tx) save() error {
+	data, err := json.Marshal(tx.rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(tx.path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// touch records name before its first write, backing up its current value.
+func (tx *Tx) touch(name string) error {
+	if slices.ContainsFunc(tx.rec.Items, func(it item) bool { return it.Name == name }) {
+		return nil
+	}
+	it := item{Name: name}
+	if old, err := tx.store.Get(name); err == nil {
+		if err := tx.store.Set(backupPrefix+name, old); err != nil {
+			return fmt.Errorf("failed to back up %q: %w", name, err)
+		}
+		it.Existed = true
+	}
+	tx.rec.Items = append(tx.rec.Items, it)
+	return tx.save()
+}
+
+func (tx *Tx) Set(name, value string) error {
+	if err := tx.touch(name); err != nil {
+		return err
+	}
+	return tx.store.Set(name, value)
+}
+
+func (tx *Tx) Get(name string) (string, error) {
+	return tx.store.Get(name)
+}
+
+func (tx *Tx) Delete(name string) error {
+	if err := tx.touch(name); err != nil {
+		return err
+	}
+	return tx.store.Delete(name)
+}
+
+// Commit marks the operation complete and discards the backups.
+func (tx *Tx) Commit() error {
+	tx.rec.State = stateCommitted
+	if err := tx.save(); err != nil {
+		return err
+	}
+	return tx.finish()
+}
+
+// Rollback restores the keychain items and the config file to their state
+// before the transaction. If it fails, the journal is kept for Recover.
+func (tx *Tx) Rollback() error {
+	for i := len(tx.rec.Items) - 1; i >= 0; i-- {
+		it := tx.rec.Items[i]
+		if !it.Existed {
+			if _, err := tx.store.Get(it.Name); err != nil {
+				continue // never written
+			}
+			if err := tx.store.Delete(it.Name); err != nil {
+				return fmt.Errorf("failed to roll back %q: %w", it.Name, err)
+			}
+			continue
+		}
+		old, err := tx.store.Get(backupPrefix + it.Name)
+		if err != nil {
+			return fmt.Errorf("failed to roll back %q: %w", it.Name, err)
+		}
+		if err := tx.store.Set(it.Name, old); err != nil {
+			return fmt.Errorf("failed to roll back %q: %w", it.Name, err)
+		}
+	}
+
+	if tx.rec.HadConfig {
+		if err := fsutil.WriteFileAtomic(tx.configPath, tx.rec.Config, 0o600); err != nil {
+			return fmt.Errorf("failed to restore config file: %w", err)
+		}
+	} else if err := os.Remove(tx.configPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to restore config file: %w", err)
+	}
+	return tx.finish()
+}
+
+// finish deletes the backups and the journal.
+func (tx *Tx) finish() error {
+	for _, it := range tx.rec.Items {
+		if it.Existed {
+			_ = tx.store.Delete(backupPrefix + it.Name)
+		}
+	}
+	if err := os.Remove(tx.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove journal: %w", err)
+	}
+	return nil
+}
+
+// Recover completes or rolls back a transaction left behind by an
+// interrupted run. It returns the operation's name (empty if there was
+// nothing to recover) and whether it was rolled back.
+func Recover(store keychain.Store, configPath string) (string, bool, error) {
+	tx := &Tx{store: store, path: Path(configPath), configPath: configPath}
+	data, err := os.ReadFile(tx.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to read journal: %w", err)
+	}
+	if err := json.Unmarshal(data, &tx.rec); err != nil {
+		return "", false, fmt.Errorf("journal %s is corrupt: %w", tx.path, err)
+	}
+
+	if tx.rec.State == stateCommitted {
+		return tx.rec.Op, false, tx.finish()
+	}
+	return tx.rec.Op, true, tx.Rollback()
+}
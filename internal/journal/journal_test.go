@@ -0,0 +1,141 @@
+package journal_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eazyhozy/sekret/internal/journal"
+	"github.com/eazyhozy/sekret/internal/keychain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupJournal(t *testing.T) (*keychain.MockStore, string) {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"version":1}`), 0o600))
+	return keychain.NewMockStore(), configPath
+}
+
+func TestCommit(t *testing.T) {
+	store, configPath := setupJournal(t)
+	require.NoError(t, store.Set("OLD_KEY", "old"))
+
+	tx, err := journal.Begin(store, configPath, "add")
+	require.NoError(t, err)
+	require.NoError(t, tx.Set("NEW_KEY", "new"))
+	require.NoError(t, tx.Set("OLD_KEY", "changed"))
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"version":2}`), 0o600))
+	require.NoError(t, tx.Commit())
+
+	val, _ := store.Get("OLD_KEY")
+	assert.Equal(t, "changed", val)
+	_, err = store.Get("journal:OLD_KEY")
+	assert.Error(t, err, "backup should be discarded")
+	assert.NoFileExists(t, journal.Path(configPath))
+}
+
+func TestRollback(t *testing.T) {
+	store, configPath := setupJournal(t)
+	require.NoError(t, store.Set("OLD_KEY", "old"))
+
+	tx, err := journal.Begin(store, configPath, "remove")
+	require.NoError(t, err)
+	require.NoError(t, tx.Set("NEW_KEY", "new"))
+	require.NoError(t, tx.Delete("OLD_KEY"))
+	require.NoError(t, os.WriteFile(configPath, []byte(`{"version":2}`), 0o600))
+	require.NoError(t, tx.Rollback())
+
+	_, err = store.Get("NEW_KEY")
+	assert.Error(t, err)
+	val, err := store.Get("OLD_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "old", val)
+
+	data, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, `{"version":1}`, string(data))
+	assert.NoFileExists(t, journal.Path(configPath))
+}
+
+func TestRollback_RemovesNewConfig(t *testing.T) {
+	store := keychain.NewMockStore()
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	tx, err := journal.Begin(store, configPath, "add")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(configPath, []byte(`{}`), 0o600))
+	require.NoError(t, tx.Rollback())
+
+	assert.NoFileExists(t, configPath)
+}
+
+func TestRecover_Interrupted(t *testing.T) {
+	store, configPath := setupJournal(t)
+	require.NoError(t, store.Set("OLD_KEY", "old"))
+
+	tx, err := journal.Begin(store, configPath, "set")
+	require.NoError(t, err)
+	require.NoError(t, tx.Set("OLD_KEY", "new"))
+	// The process dies here, before the config is saved.
+
+	_, err = journal.Begin(store, configPath, "add")
+	assert.Error(t, err, "a pending journal blocks new transactions")
+
+	op, rolledBack, err := journal.Recover(store, configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "set", op)
+	assert.True(t, rolledBack)
+	val, _ := store.Get("OLD_KEY")
+	assert.Equal(t, "old", val)
+}
+
+func TestRecover_Committed(t *testing.T) {
+	store, configPath := setupJournal(t)
+	require.NoError(t, store.Set("OLD_KEY", "old"))
+	faults := keychain.NewFaultStore(store)
+
+	tx, err := journal.Begin(faults, configPath, "set")
+	require.NoError(t, err)
+	require.NoError(t, tx.Set("OLD_KEY", "new"))
+	faults.FailAfter(0) // backups can't be discarded
+	require.NoError(t, tx.Commit())
+	assert.NoFileExists(t, journal.Path(configPath))
+
+	faults.FailAfter(-1)
+	op, _, err := journal.Recover(faults, configPath)
+	require.NoError(t, err)
+	assert.Empty(t, op, "nothing left to recover")
+	val, _ := store.Get("OLD_KEY")
+	assert.Equal(t, "new", val)
+}
+
+func TestRollback_FailureKeepsJournal(t *testing.T) {
+	store, configPath := setupJournal(t)
+	require.NoError(t, store.Set("OLD_KEY", "old"))
+	faults := keychain.NewFaultStore(store)
+
+	tx, err := journal.Begin(faults, configPath, "import")
+	require.NoError(t, err)
+	require.NoError(t, tx.Set("NEW_KEY", "new"))
+	require.NoError(t, tx.Set("OLD_KEY", "changed"))
+	faults.FailAfter(0)
+	assert.Error(t, tx.Set("THIRD_KEY", "third"))
+
+	// The keychain is still failing, so the rollback cannot complete.
+	assert.Error(t, tx.Rollback())
+	assert.FileExists(t, journal.Path(configPath))
+
+	faults.FailAfter(-1)
+	op, rolledBack, err := journal.Recover(faults, configPath)
+	require.NoError(t, err)
+	assert.Equal(t, "import", op)
+	assert.True(t, rolledBack)
+
+	_, err = store.Get("NEW_KEY")
+	assert.Error(t, err)
+	val, _ := store.Get("OLD_KEY")
+	assert.Equal(t, "old", val)
+	assert.NoFileExists(t, journal.Path(configPath))
+}
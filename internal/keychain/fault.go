@@ -0,0 +1,56 @@
+package keychain
+
+import "fmt"
+
+// FaultStore wraps a Store and makes writes fail on demand, to test how
+// multi-step operations cope with a keychain failing halfway.
+type FaultStore struct {
+	Store
+	remaining int  // writes that succeed before failures start; -1 never fails
+	once      bool // only the first failing write fails
+}
+
+func (f *FaultStore) fail(op, name string) error {
+	if f.remaining < 0 {
+		return nil
+	}
+	if f.remaining == 0 {
+		if f.once {
+			f.remaining = -1
+		}
+		return fmt.Errorf("failed to %s key %q in keychain: injected fault", op, name)
+	}
+	f.remaining--
+	return nil
+}
+
+func (f *FaultStore) Set(name, value string) error {
+	if err := f.fail("save", name); err != nil {
+		return err
+	}
+	return f.Store.Set(name, value)
+}
+
+func (f *FaultStore) Delete(name string) error {
+	if err := f.fail("delete", name); err != nil {
+		return err
+	}
+	return f.Store.Delete(name)
+}
+
+// FailAfter lets n more writes succeed and fails every write after that.
+// A negative n turns fault injection off.
+func (f *FaultStore) FailAfter(n int) {
+	f.remaining, f.once = n, false
+}
+
+// FailOnceAfter lets n more writes succeed, fails the next one and then
+// recovers.
+func (f *FaultStore) FailOnceAfter(n int) {
+	f.remaining, f.once = n, true
+}
+
+// NewFaultStore returns a FaultStore over s that does not fail until told to.
+func NewFaultStore(s Store) *FaultStore {
+	return &FaultStore{Store: s, remaining: -1}
+}
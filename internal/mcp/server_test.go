@@ -0,0 +1,108 @@
+package mcp_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/eazyhozy/sekret/internal/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve runs the server over the given request lines and decodes each response.
+func serve(t *testing.T, s *mcp.Server, lines ...string) []map[string]any {
+	t.Helper()
+	var out bytes.Buffer
+	require.NoError(t, s.Serve(strings.NewReader(strings.Join(lines, "\n")+"\n"), &out))
+
+	var responses []map[string]any
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var resp map[string]any
+		require.NoError(t, dec.Decode(&resp))
+		responses = append(responses, resp)
+	}
+	return responses
+}
+
+func echoServer() *mcp.Server {
+	s := mcp.NewServer("sekret", "test")
+	s.AddTool(mcp.Tool{
+		Name:        "echo",
+		Description: "Echo the message",
+		InputSchema: map[string]any{"type": "object"},
+		Handler: func(args json.RawMessage) (string, error) {
+			var p struct {
+				Message string `json:"message"`
+			}
+			if err := json.Unmarshal(args, &p); err != nil {
+				return "", err
+			}
+			if p.Message == "" {
+				return "", fmt.Errorf("message is required")
+			}
+			return p.Message, nil
+		},
+	})
+	return s
+}
+
+func TestServe_Initialize(t *testing.T) {
+	resps := serve(t, echoServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+	)
+
+	require.Len(t, resps, 1, "notifications get no response")
+	result := resps[0]["result"].(map[string]any)
+	assert.Equal(t, "2024-11-05", result["protocolVersion"])
+	assert.Equal(t, "sekret", result["serverInfo"].(map[string]any)["name"])
+	assert.Contains(t, result["capabilities"], "tools")
+}
+
+func TestServe_UnsupportedVersionFallsBack(t *testing.T) {
+	resps := serve(t, echoServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"1999-01-01"}}`)
+
+	result := resps[0]["result"].(map[string]any)
+	assert.Equal(t, "2025-06-18", result["protocolVersion"])
+}
+
+func TestServe_ListAndCallTools(t *testing.T) {
+	resps := serve(t, echoServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"echo","arguments":{"message":"hi"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"echo","arguments":{}}}`,
+	)
+	require.Len(t, resps, 3)
+
+	tools := resps[0]["result"].(map[string]any)["tools"].([]any)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "echo", tools[0].(map[string]any)["name"])
+
+	ok := resps[1]["result"].(map[string]any)
+	assert.Equal(t, false, ok["isError"])
+	assert.Equal(t, "hi", ok["content"].([]any)[0].(map[string]any)["text"])
+
+	failed := resps[2]["result"].(map[string]any)
+	assert.Equal(t, true, failed["isError"])
+	assert.Equal(t, "message is required", failed["content"].([]any)[0].(map[string]any)["text"])
+}
+
+func TestServe_Errors(t *testing.T) {
+	resps := serve(t, echoServer(),
+		`not json`,
+		`{"jsonrpc":"2.0","id":1,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"nope"}}`,
+	)
+	require.Len(t, resps, 3)
+
+	codes := make([]float64, 0, len(resps))
+	for _, r := range resps {
+		codes = append(codes, r["error"].(map[string]any)["code"].(float64))
+	}
+	assert.Equal(t, []float64{-32700, -32601, -32602}, codes)
+}
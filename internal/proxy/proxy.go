@@ -0,0 +1,92 @@
+package proxy
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+)
+
+// placeholderPrefix marks dummy keys handed to clients of the proxy.
+const placeholderPrefix = "sekret-proxy-"
+
+// Route forwards requests under /<Name>/ to Upstream, replacing the
+// placeholder in Header with the real secret.
+type Route struct {
+	Name     string
+	Upstream *url.URL
+	Header   string // e.g. "Authorization" or "x-api-key"
+	Scheme   string // e.g. "Bearer"; empty if the header holds the raw key
+	Secret   string
+}
+
+// headerValue formats a key for the route's auth header.
+func (r *Route) headerValue(key string) string {
+	if r.Scheme == "" {
+		return key
+	}
+	return r.Scheme + " " + key
+}
+
+// NewPlaceholder returns a random dummy key. Only requests carrying it are
+// forwarded, so other local processes cannot use the proxy.
+func NewPlaceholder() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate placeholder: %w", err)
+	}
+	return placeholderPrefix + hex.EncodeToString(b), nil
+}
+
+// Handler is an HTTP reverse proxy that injects real keys into requests.
+type Handler struct {
+	routes      map[string]*Route
+	placeholder string
+	proxy       *httputil.ReverseProxy
+}
+
+// New returns a handler serving the given routes. Clients must send
+// placeholder wherever they would send the real key.
+func New(routes []Route, placeholder string) *Handler {
+	h := &Handler{routes: map[string]*Route{}, placeholder: placeholder}
+	for i := range routes {
+		h.routes[routes[i].Name] = &routes[i]
+	}
+	h.proxy = &httputil.ReverseProxy{Rewrite: h.rewrite}
+	return h
+}
+
+// ServeHTTP checks the placeholder and forwards the request.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	route, _ := h.match(req.URL.Path)
+	if route == nil {
+		http.Error(w, "sekret proxy: unknown route", http.StatusNotFound)
+		return
+	}
+	if req.Header.Get(route.Header) != route.headerValue(h.placeholder) {
+		http.Error(w, "sekret proxy: missing or invalid placeholder key", http.StatusUnauthorized)
+		return
+	}
+	h.proxy.ServeHTTP(w, req)
+}
+
+// match splits /<name>/rest into its route and the upstream path.
+func (h *Handler) match(path string) (*Route, string) {
+	name, rest, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	route, ok := h.routes[name]
+	if !ok {
+		return nil, ""
+	}
+	return route, "/" + rest
+}
+
+func (h *Handler) rewrite(pr *httputil.ProxyRequest) {
+	route, rest := h.match(pr.In.URL.Path)
+	pr.Out.URL.Path = rest
+	pr.Out.URL.RawPath = ""
+	pr.SetURL(route.Upstream)
+	pr.Out.Header.Set(route.Header, route.headerValue(route.Secret))
+}
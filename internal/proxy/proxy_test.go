@@ -0,0 +1,111 @@
+package proxy_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/eazyhozy/sekret/internal/proxy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upstream records the last request it received.
+type upstream struct {
+	server *httptest.Server
+	last   *http.Request
+	body   string
+}
+
+func newUpstream(t *testing.T) *upstream {
+	t.Helper()
+	u := &upstream{}
+	u.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		u.last = r
+		u.body = string(body)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	t.Cleanup(u.server.Close)
+	return u
+}
+
+func newProxy(t *testing.T, routes ...proxy.Route) (*httptest.Server, string) {
+	t.Helper()
+	placeholder, err := proxy.NewPlaceholder()
+	require.NoError(t, err)
+	srv := httptest.NewServer(proxy.New(routes, placeholder))
+	t.Cleanup(srv.Close)
+	return srv, placeholder
+}
+
+func TestProxy_InjectsBearerToken(t *testing.T) {
+	up := newUpstream(t)
+	target, _ := url.Parse(up.server.URL + "/base")
+	srv, placeholder := newProxy(t, proxy.Route{
+		Name: "OPENAI_API_KEY", Upstream: target, Header: "Authorization", Scheme: "Bearer", Secret: "sk-real",
+	})
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/OPENAI_API_KEY/v1/chat/completions?x=1", strings.NewReader(`{"model":"m"}`))
+	req.Header.Set("Authorization", "Bearer "+placeholder)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotNil(t, up.last)
+	assert.Equal(t, "/base/v1/chat/completions", up.last.URL.Path)
+	assert.Equal(t, "x=1", up.last.URL.RawQuery)
+	assert.Equal(t, "Bearer sk-real", up.last.Header.Get("Authorization"))
+	assert.Equal(t, `{"model":"m"}`, up.body)
+}
+
+func TestProxy_InjectsRawHeader(t *testing.T) {
+	up := newUpstream(t)
+	target, _ := url.Parse(up.server.URL)
+	srv, placeholder := newProxy(t, proxy.Route{
+		Name: "ANTHROPIC_API_KEY", Upstream: target, Header: "x-api-key", Secret: "sk-ant-real",
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/ANTHROPIC_API_KEY/v1/models", nil)
+	req.Header.Set("x-api-key", placeholder)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "sk-ant-real", up.last.Header.Get("x-api-key"))
+	assert.Equal(t, "/v1/models", up.last.URL.Path)
+}
+
+func TestProxy_RejectsWrongPlaceholder(t *testing.T) {
+	up := newUpstream(t)
+	target, _ := url.Parse(up.server.URL)
+	srv, _ := newProxy(t, proxy.Route{
+		Name: "OPENAI_API_KEY", Upstream: target, Header: "Authorization", Scheme: "Bearer", Secret: "sk-real",
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/OPENAI_API_KEY/v1/models", nil)
+	req.Header.Set("Authorization", "Bearer guessed")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Nil(t, up.last, "request should not reach upstream")
+}
+
+func TestProxy_UnknownRoute(t *testing.T) {
+	srv, placeholder := newProxy(t)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/NOPE/v1", nil)
+	req.Header.Set("Authorization", "Bearer "+placeholder)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
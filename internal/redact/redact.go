@@ -0,0 +1,103 @@
+package redact
+
+import (
+	"bytes"
+	"io"
+	"sort"
+
+	"github.com/eazyhozy/sekret/internal/scanner"
+)
+
+// MinLength is the shortest value that is redacted. Shorter values would
+// mask unrelated output (a one-character secret matches everywhere).
+const MinLength = 4
+
+type secret struct {
+	value []byte
+	mask  []byte
+}
+
+// Writer replaces secret values written to it with masked placeholders
+// before passing the data on. A value split across several writes is still
+// caught: bytes that may start a secret are held back until the next write
+// or Flush.
+type Writer struct {
+	w       io.Writer
+	secrets []secret
+	buf     []byte
+}
+
+// NewWriter returns a Writer that masks values on their way to w.
+// Values shorter than MinLength are ignored.
+func NewWriter(w io.Writer, values []string) *Writer {
+	rw := &Writer{w: w}
+	for _, v := range values {
+		if len(v) < MinLength {
+			continue
+		}
+		rw.secrets = append(rw.secrets, secret{value: []byte(v), mask: []byte(scanner.MaskValue(v))})
+	}
+	// Longest first, so a value containing another is masked as a whole.
+	sort.SliceStable(rw.secrets, func(i, j int) bool {
+		return len(rw.secrets[i].value) > len(rw.secrets[j].value)
+	})
+	return rw
+}
+
+// Write masks secrets in p and writes everything that can no longer be
+// part of a secret. It always reports len(p) bytes consumed on success.
+func (rw *Writer) Write(p []byte) (int, error) {
+	rw.buf = append(rw.buf, p...)
+	if err := rw.drain(false); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes any held-back bytes. Call it once the stream has ended.
+func (rw *Writer) Flush() error {
+	return rw.drain(true)
+}
+
+// drain masks and writes buf. Unless final, a tail that is a prefix of
+// some secret is kept for the next call.
+func (rw *Writer) drain(final bool) error {
+	var out bytes.Buffer
+	i := 0
+scan:
+	for i < len(rw.buf) {
+		rest := rw.buf[i:]
+		if !final {
+			for _, s := range rw.secrets {
+				if len(rest) < len(s.value) && bytes.HasPrefix(s.value, rest) {
+					break scan
+				}
+			}
+		}
+		for _, s := range rw.secrets {
+			if bytes.HasPrefix(rest, s.value) {
+				out.Write(s.mask)
+				i += len(s.value)
+				continue scan
+			}
+		}
+		out.WriteByte(rw.buf[i])
+		i++
+	}
+
+	rw.buf = append(rw.buf[:0], rw.buf[i:]...)
+	if out.Len() == 0 {
+		return nil
+	}
+	_, err := rw.w.Write(out.Bytes())
+	return err
+}
+
+// String masks every secret value in s.
+func String(s string, values []string) string {
+	var out bytes.Buffer
+	rw := NewWriter(&out, values)
+	_, _ = rw.Write([]byte(s))
+	_ = rw.Flush()
+	return out.String()
+}
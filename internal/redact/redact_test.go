@@ -0,0 +1,78 @@
+package redact_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/eazyhozy/sekret/internal/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		values []string
+		want   string
+	}{
+		{"single", "key=sk-proj-abcdefgh1234\n", []string{"sk-proj-abcdefgh1234"}, "key=sk-proj-...1234\n"},
+		{"repeated", "abcdefghij abcdefghij", []string{"abcdefghij"}, "abcd...ghij abcd...ghij"},
+		{"no match", "nothing here", []string{"abcdefghij"}, "nothing here"},
+		{"short value ignored", "a b c", []string{"b"}, "a b c"},
+		{"longest wins", "token=abcdefghij-extra", []string{"abcdefghij", "abcdefghij-extra"}, "token=abcd...xtra"},
+		{"partial at end", "abcdefg", []string{"abcdefghij"}, "abcdefg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, redact.String(tt.input, tt.values))
+		})
+	}
+}
+
+func TestWriter_SplitAcrossWrites(t *testing.T) {
+	secret := "ghp_abcdefghijklmnop"
+	input := "token: " + secret + " done\n"
+
+	// Every split point, including inside the secret.
+	for split := 0; split <= len(input); split++ {
+		var out bytes.Buffer
+		w := redact.NewWriter(&out, []string{secret})
+		_, err := w.Write([]byte(input[:split]))
+		require.NoError(t, err)
+		_, err = w.Write([]byte(input[split:]))
+		require.NoError(t, err)
+		require.NoError(t, w.Flush())
+
+		assert.Equal(t, "token: ghp_...mnop done\n", out.String(), "split at %d", split)
+	}
+}
+
+func TestWriter_ByteAtATime(t *testing.T) {
+	secret := "sk-ant-0123456789"
+	input := "a" + secret + "b" + secret
+
+	var out bytes.Buffer
+	w := redact.NewWriter(&out, []string{secret})
+	for i := 0; i < len(input); i++ {
+		_, err := w.Write([]byte{input[i]})
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Flush())
+
+	assert.NotContains(t, out.String(), secret)
+	assert.Equal(t, "a"+"sk-ant-...6789"+"b"+"sk-ant-...6789", out.String())
+}
+
+func TestWriter_HoldsOnlyPossiblePrefix(t *testing.T) {
+	var out bytes.Buffer
+	w := redact.NewWriter(&out, []string{"abcdefghij"})
+
+	_, err := w.Write([]byte("hello abc"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello ", out.String(), "a possible secret prefix is held back")
+
+	_, err = w.Write([]byte("xyz"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello abcxyz", out.String())
+}
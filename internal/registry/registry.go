@@ -7,14 +7,28 @@ type Entry struct {
 	Name     string
 	EnvVar   string
 	Prefixes []string
+	Upstream *Upstream // nil if the key cannot be used through the proxy
+}
+
+// Upstream describes a provider API that the proxy can inject the key into.
+type Upstream struct {
+	URL        string // e.g. https://api.openai.com
+	BaseURLEnv string // env var SDKs read the base URL from
+	BasePath   string // suffix SDKs expect on the base URL, e.g. /v1
+	Header     string // header carrying the key
+	Scheme     string // header value prefix, e.g. Bearer; empty for the raw key
 }
 
 var builtinEntries = []Entry{
-	{Name: "openai", EnvVar: "OPENAI_API_KEY", Prefixes: []string{"sk-", "sk-proj-"}},
-	{Name: "anthropic", EnvVar: "ANTHROPIC_API_KEY", Prefixes: []string{"sk-ant-"}},
-	{Name: "gemini", EnvVar: "GEMINI_API_KEY", Prefixes: []string{"AIza"}},
+	{Name: "openai", EnvVar: "OPENAI_API_KEY", Prefixes: []string{"sk-", "sk-proj-"},
+		Upstream: &Upstream{URL: "https://api.openai.com", BaseURLEnv: "OPENAI_BASE_URL", BasePath: "/v1", Header: "Authorization", Scheme: "Bearer"}},
+	{Name: "anthropic", EnvVar: "ANTHROPIC_API_KEY", Prefixes: []string{"sk-ant-"},
+		Upstream: &Upstream{URL: "https://api.anthropic.com", BaseURLEnv: "ANTHROPIC_BASE_URL", Header: "x-api-key"}},
+	{Name: "gemini", EnvVar: "GEMINI_API_KEY", Prefixes: []string{"AIza"},
+		Upstream: &Upstream{URL: "https://generativelanguage.googleapis.com", BaseURLEnv: "GOOGLE_GEMINI_BASE_URL", Header: "x-goog-api-key"}},
 	{Name: "github", EnvVar: "GITHUB_TOKEN", Prefixes: []string{"ghp_", "github_pat_"}},
-	{Name: "groq", EnvVar: "GROQ_API_KEY", Prefixes: []string{"gsk_"}},
+	{Name: "groq", EnvVar: "GROQ_API_KEY", Prefixes: []string{"gsk_"},
+		Upstream: &Upstream{URL: "https://api.groq.com", BaseURLEnv: "GROQ_BASE_URL", Header: "Authorization", Scheme: "Bearer"}},
 }
 
 // All returns all built-in registry entries.
@@ -92,3 +92,15 @@ func TestLookupByEnvVar(t *testing.T) {
 		})
 	}
 }
+
+func TestUpstreams_Complete(t *testing.T) {
+	for _, e := range registry.All() {
+		if e.Upstream == nil {
+			continue
+		}
+		assert.NotEmpty(t, e.Upstream.URL, e.Name)
+		assert.NotEmpty(t, e.Upstream.BaseURLEnv, e.Name)
+		assert.NotEmpty(t, e.Upstream.Header, e.Name)
+	}
+	assert.Nil(t, registry.Lookup("github").Upstream, "github tokens are not proxied")
+}
@@ -0,0 +1,100 @@
+package rewrite
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/eazyhozy/sekret/internal/fsutil"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Edit replaces or deletes one line of a file.
+type Edit struct {
+	Line   int    // 1-based line number
+	Old    string // the line's expected content, without the newline
+	New    string // replacement line; ignored when Delete is set
+	Delete bool   // remove the line instead of replacing it
+}
+
+// backupTimeFormat timestamps backup file names.
+const backupTimeFormat = "20060102-150405"
+
+// Apply returns content with edits applied. It fails if a line does not
+// hold its expected content, e.g. because the file changed since it was
+// scanned.
+func Apply(content string, edits []Edit) (string, error) {
+	lines := strings.Split(content, "\n")
+	sorted := append([]Edit(nil), edits...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Line > sorted[j].Line })
+
+	for i, e := range sorted {
+		if i > 0 && sorted[i-1].Line == e.Line {
+			return "", fmt.Errorf("line %d is edited twice", e.Line)
+		}
+		if e.Line < 1 || e.Line > len(lines) || lines[e.Line-1] != e.Old {
+			return "", fmt.Errorf("line %d has changed since it was scanned", e.Line)
+		}
+		if e.Delete {
+			lines = append(lines[:e.Line-1], lines[e.Line:]...)
+		} else {
+			lines[e.Line-1] = e.New
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// Diff returns a unified diff from before to after for the file at path.
+func Diff(path, before, after string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(before),
+		B:        difflib.SplitLines(after),
+		FromFile: path,
+		ToFile:   path,
+		Context:  3,
+	})
+}
+
+// Backup copies the file at path to a timestamped file next to it, readable
+// only by the owner, and returns the backup's path.
+func Backup(path string, now time.Time) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	backup := fmt.Sprintf("%s.sekret-backup-%s", path, now.Format(backupTimeFormat))
+	for n := 2; ; n++ {
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			break
+		}
+		backup = fmt.Sprintf("%s.sekret-backup-%s-%d", path, now.Format(backupTimeFormat), n)
+	}
+	if err := os.WriteFile(backup, data, 0o600); err != nil {
+		return "", fmt.Errorf("failed to back up %s: %w", filepath.Base(path), err)
+	}
+	return backup, nil
+}
+
+// Write replaces the file at path with content if it still holds expected,
+// keeping its permissions. If path is a symlink, its target is replaced.
+func Write(path, expected, content string) error {
+	path, err := fsutil.ResolveSymlinks(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if string(current) != expected {
+		return fmt.Errorf("%s changed while waiting for confirmation", path)
+	}
+	return fsutil.WriteFileAtomic(path, []byte(content), info.Mode().Perm())
+}
@@ -0,0 +1,81 @@
+package rewrite_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eazyhozy/sekret/internal/rewrite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApply(t *testing.T) {
+	content := "one\ntwo\nthree\nfour\n"
+	out, err := rewrite.Apply(content, []rewrite.Edit{
+		{Line: 2, Old: "two", New: "# two"},
+		{Line: 3, Old: "three", Delete: true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "one\n# two\nfour\n", out)
+}
+
+func TestApply_ChangedLine(t *testing.T) {
+	_, err := rewrite.Apply("one\ntwo\n", []rewrite.Edit{{Line: 2, Old: "TWO", Delete: true}})
+	assert.ErrorContains(t, err, "line 2 has changed")
+
+	_, err = rewrite.Apply("one\n", []rewrite.Edit{{Line: 9, Old: "nine", Delete: true}})
+	assert.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	diff, err := rewrite.Diff("rc", "a\nb\n", "a\n# b\n")
+	require.NoError(t, err)
+	assert.Contains(t, diff, "--- rc\n+++ rc\n")
+	assert.Contains(t, diff, "-b\n+# b\n")
+}
+
+func TestBackupAndWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".zshrc")
+	require.NoError(t, os.WriteFile(path, []byte("before\n"), 0o644))
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.Local)
+
+	backup, err := rewrite.Backup(path, now)
+	require.NoError(t, err)
+	assert.Equal(t, path+".sekret-backup-20260102-030405", backup)
+	second, err := rewrite.Backup(path, now)
+	require.NoError(t, err)
+	assert.NotEqual(t, backup, second, "existing backups are not overwritten")
+
+	assert.Error(t, rewrite.Write(path, "something else\n", "after\n"))
+	require.NoError(t, rewrite.Write(path, "before\n", "after\n"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "after\n", string(data))
+	data, err = os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, "before\n", string(data))
+	info, err := os.Stat(backup)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestWrite_Symlink(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "zshrc")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0o755))
+	require.NoError(t, os.WriteFile(target, []byte("before\n"), 0o644))
+	link := filepath.Join(dir, ".zshrc")
+	require.NoError(t, os.Symlink(target, link))
+
+	require.NoError(t, rewrite.Write(link, "before\n", "after\n"))
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode()&os.ModeSymlink, "the link should be kept")
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "after\n", string(data))
+}
@@ -16,6 +16,8 @@ type Finding struct {
 	Line     int
 	EnvVar   string
 	Value    string
+	Plain    bool   // true for non-secret exports (only returned by ScanFileAll)
+	Raw      string // the line as read, to check it is unchanged before rewriting
 }
 
 // secretSuffixes are env var name suffixes that indicate a secret value.
@@ -24,6 +26,21 @@ var secretSuffixes = []string{"_KEY", "_TOKEN", "_SECRET", "_CREDENTIALS"}
 // exportPattern matches `export KEY=VALUE` statements.
 var exportPattern = regexp.MustCompile(`^\s*export\s+([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
 
+// awsCredentialPattern matches key lines in ~/.aws/credentials (INI format).
+var awsCredentialPattern = regexp.MustCompile(`^\s*(aws_access_key_id|aws_secret_access_key|aws_session_token)\s*=\s*(.*)$`)
+
+// iniSectionPattern matches section headers such as [default].
+var iniSectionPattern = regexp.MustCompile(`^\s*\[\s*([^\]]*?)\s*\]`)
+
+// awsDefaultProfile is the credentials profile the AWS env vars stand for.
+// Keys of other profiles are reported under a profile-suffixed name, e.g.
+// AWS_SECRET_ACCESS_KEY_WORK for [work], so importing them does not
+// clobber the default credentials.
+const awsDefaultProfile = "default"
+
+// profileNamePattern matches characters not allowed in env var names into underscores.
+var profileNamePattern = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
 // knownPrefixes are API key prefixes used for mask display (longest first).
 var knownPrefixes = []string{
 	"sk-proj-", "sk-ant-", "github_pat_",
@@ -37,15 +54,42 @@ var defaultTargetFiles = []string{
 	".profile",
 }
 
+// credentialTargetFiles are tool credential files that store keys in plaintext.
+// They are flagged by scan but are not shell config files.
+var credentialTargetFiles = []string{
+	filepath.Join(".aws", "credentials"),
+}
+
 // DefaultTargets returns the default scan target paths in the home directory.
 func DefaultTargets() []string {
+	return homeTargets(defaultTargetFiles)
+}
+
+// CredentialTargets returns plaintext credential files in the home directory
+// that should be flagged in addition to the shell config files.
+func CredentialTargets() []string {
+	return homeTargets(credentialTargetFiles)
+}
+
+// isCredentialFile reports whether path is one of the credential files,
+// wherever its home directory is.
+func isCredentialFile(path string) bool {
+	for _, f := range credentialTargetFiles {
+		if strings.HasSuffix(filepath.Clean(path), string(filepath.Separator)+f) {
+			return true
+		}
+	}
+	return false
+}
+
+func homeTargets(files []string) []string {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return nil
 	}
 
-	targets := make([]string, len(defaultTargetFiles))
-	for i, f := range defaultTargetFiles {
+	targets := make([]string, len(files))
+	for i, f := range files {
 		targets[i] = filepath.Join(home, f)
 	}
 	return targets
@@ -53,6 +97,17 @@ func DefaultTargets() []string {
 
 // ScanFile parses a single file for export statements containing potential secrets.
 func ScanFile(path string) ([]Finding, error) {
+	return scanFile(path, false)
+}
+
+// ScanFileAll parses a single file for all export statements with static values.
+// Exports that do not look like secrets are returned with Plain set.
+// Values that reference other variables or commands ($, `) are skipped.
+func ScanFileAll(path string) ([]Finding, error) {
+	return scanFile(path, true)
+}
+
+func scanFile(path string, includePlain bool) ([]Finding, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -62,6 +117,8 @@ func ScanFile(path string) ([]Finding, error) {
 	var findings []Finding
 	lineNum := 0
 	s := bufio.NewScanner(f)
+	credentials := isCredentialFile(path)
+	section := ""
 
 	for s.Scan() {
 		lineNum++
@@ -72,23 +129,37 @@ func ScanFile(path string) ([]Finding, error) {
 			continue
 		}
 
-		matches := exportPattern.FindStringSubmatch(line)
-		if matches == nil {
+		var envVar, value string
+		if matches := exportPattern.FindStringSubmatch(line); matches != nil {
+			envVar = matches[1]
+			value = unquote(matches[2])
+		} else if !credentials {
+			continue
+		} else if matches := iniSectionPattern.FindStringSubmatch(line); matches != nil {
+			section = matches[1]
+			continue
+		} else if matches := awsCredentialPattern.FindStringSubmatch(line); matches != nil {
+			envVar = strings.ToUpper(matches[1])
+			value = strings.TrimSpace(matches[2])
+		} else {
 			continue
 		}
 
-		envVar := matches[1]
-		value := unquote(matches[2])
-
-		if !IsSecretEnvVar(envVar) {
+		plain := !IsSecretEnvVar(envVar)
+		if plain && (!includePlain || strings.ContainsAny(value, "$`")) {
 			continue
 		}
+		if credentials && section != awsDefaultProfile {
+			envVar += "_" + strings.ToUpper(profileNamePattern.ReplaceAllString(section, "_"))
+		}
 
 		findings = append(findings, Finding{
 			FilePath: path,
 			Line:     lineNum,
 			EnvVar:   envVar,
 			Value:    value,
+			Plain:    plain,
+			Raw:      line,
 		})
 	}
 
@@ -101,9 +172,18 @@ func ScanFile(path string) ([]Finding, error) {
 // ScanFiles scans multiple files and returns all findings.
 // Files that do not exist are silently skipped.
 func ScanFiles(paths []string) ([]Finding, error) {
+	return scanFiles(paths, ScanFile)
+}
+
+// ScanFilesAll is like ScanFiles but also returns non-secret exports.
+func ScanFilesAll(paths []string) ([]Finding, error) {
+	return scanFiles(paths, ScanFileAll)
+}
+
+func scanFiles(paths []string, scan func(string) ([]Finding, error)) ([]Finding, error) {
 	var all []Finding
 	for _, path := range paths {
-		findings, err := ScanFile(path)
+		findings, err := scan(path)
 		if err != nil {
 			if os.IsNotExist(err) {
 				continue
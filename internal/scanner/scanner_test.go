@@ -30,6 +30,7 @@ func TestScanFile_DoubleQuoted(t *testing.T) {
 	assert.Equal(t, "OPENAI_API_KEY", f.EnvVar)
 	assert.Equal(t, "sk-proj-abc123", f.Value)
 	assert.Equal(t, 1, f.Line)
+	assert.Equal(t, `export OPENAI_API_KEY="sk-proj-abc123"`, f.Raw)
 }
 
 func TestScanFile_SingleQuoted(t *testing.T) {
@@ -239,3 +240,87 @@ func TestDefaultTargets(t *testing.T) {
 		assert.Equal(t, filepath.Join(home, f), targets[i], "target %d", i)
 	}
 }
+
+func TestScanFileAll_IncludesPlainExports(t *testing.T) {
+	path := writeTempFile(t, `export OPENAI_API_KEY="sk-proj-abc123"
+export OPENAI_BASE_URL="https://api.openai.com/v1"
+export PATH="$PATH:/usr/local/bin"
+export NOW=`+"`date`"+`
+`)
+
+	findings, err := ScanFileAll(path)
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+
+	assert.Equal(t, "OPENAI_API_KEY", findings[0].EnvVar)
+	assert.False(t, findings[0].Plain)
+	assert.Equal(t, "OPENAI_BASE_URL", findings[1].EnvVar)
+	assert.Equal(t, "https://api.openai.com/v1", findings[1].Value)
+	assert.True(t, findings[1].Plain)
+}
+
+func TestScanFile_ExcludesPlainExports(t *testing.T) {
+	path := writeTempFile(t, `export OPENAI_BASE_URL="https://api.openai.com/v1"`)
+
+	findings, err := ScanFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func writeAWSCredentials(t *testing.T, content string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), ".aws")
+	require.NoError(t, os.Mkdir(dir, 0o700))
+	path := filepath.Join(dir, "credentials")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestScanFile_AWSCredentials(t *testing.T) {
+	path := writeAWSCredentials(t, `[default]
+aws_access_key_id = AKIAEXAMPLE12345
+aws_secret_access_key = wJalrXUtnFEMIEXAMPLEKEY
+`)
+
+	findings, err := ScanFile(path)
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "AWS_SECRET_ACCESS_KEY", findings[0].EnvVar)
+	assert.Equal(t, "wJalrXUtnFEMIEXAMPLEKEY", findings[0].Value)
+	assert.Equal(t, 3, findings[0].Line)
+}
+
+func TestScanFile_AWSCredentialsOtherProfiles(t *testing.T) {
+	path := writeAWSCredentials(t, `[work]
+aws_secret_access_key = wJalrXUtnFEMIWORKKEY
+[ default ]
+aws_secret_access_key = wJalrXUtnFEMIEXAMPLEKEY
+[personal-dev]
+aws_session_token = FwoGZXIvYXdzEXAMPLE
+`)
+
+	findings, err := ScanFile(path)
+	require.NoError(t, err)
+	require.Len(t, findings, 3)
+	assert.Equal(t, "AWS_SECRET_ACCESS_KEY_WORK", findings[0].EnvVar)
+	assert.Equal(t, "wJalrXUtnFEMIWORKKEY", findings[0].Value)
+	assert.Equal(t, "AWS_SECRET_ACCESS_KEY", findings[1].EnvVar)
+	assert.Equal(t, "wJalrXUtnFEMIEXAMPLEKEY", findings[1].Value)
+	assert.Equal(t, 4, findings[1].Line)
+	assert.Equal(t, "AWS_SESSION_TOKEN_PERSONAL_DEV", findings[2].EnvVar)
+}
+
+func TestScanFile_AWSKeyLinesOnlyInCredentialFiles(t *testing.T) {
+	path := writeTempFile(t, `[default]
+aws_secret_access_key = wJalrXUtnFEMIEXAMPLEKEY
+`)
+
+	findings, err := ScanFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, findings)
+}
+
+func TestCredentialTargets(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	assert.Contains(t, CredentialTargets(), filepath.Join(home, ".aws", "credentials"))
+}